@@ -4,8 +4,11 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/ry023/reviewhub/reviewhub"
 	"github.com/ry023/reviewhub/runners"
@@ -16,22 +19,13 @@ var rootCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Retrieve all source and notify",
 	Run: func(cmd *cobra.Command, args []string) {
-		cf, err := cmd.Flags().GetString("config")
-		if err != nil {
-			log.Fatalf("Failed to load flag: %v", err)
-		}
+		_, r := loadRunner(cmd)
 
-		config, err := reviewhub.NewConfig(cf)
-		if err != nil {
-			log.Fatalf("Failed to parse config file: %v", err)
-		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
 
-		r, err := runners.New(config)
-		if err != nil {
-			log.Fatalf("Failed to create runner: %v", err)
-		}
-
-		if err := r.Run(); err != nil {
+		if err := r.Run(ctx); err != nil {
+			reportHints(err)
 			log.Fatalf("Failed to run: %v", err)
 		}
 	},
@@ -45,5 +39,31 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.Flags().StringP("config", "c", "reviewhub.yaml", "config file path")
+	rootCmd.PersistentFlags().StringP("config", "c", "reviewhub.yaml", "config file path")
+}
+
+// reportHints logs actionable hints of API errors like "token in NOTION_TOKEN lacks access to database X"
+func reportHints(err error) {
+	for _, h := range reviewhub.APIErrorHints(err) {
+		log.Printf("Hint: %s", h)
+	}
+}
+
+func loadRunner(cmd *cobra.Command) (*reviewhub.Config, *runners.ReviewHubRunner) {
+	cf, err := cmd.Flags().GetString("config")
+	if err != nil {
+		log.Fatalf("Failed to load flag: %v", err)
+	}
+
+	config, err := reviewhub.NewConfig(cf)
+	if err != nil {
+		log.Fatalf("Failed to parse config file: %v", err)
+	}
+
+	r, err := runners.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create runner: %v", err)
+	}
+
+	return config, r
 }
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+	"github.com/ry023/reviewhub/scheduler"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	Aliases: []string{"daemon"},
+	Short:   "Keep running and notify on schedules in config",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, r := loadRunner(cmd)
+
+		loc, err := config.Location()
+		if err != nil {
+			log.Fatalf("Failed to load timezone: %v", err)
+		}
+
+		var jobs []scheduler.Job
+		for name, expr := range r.Schedules() {
+			s, err := scheduler.Parse(expr)
+			if err != nil {
+				log.Fatalf("Failed to parse schedule of %s: %v", name, err)
+			}
+			jobs = append(jobs, scheduler.Job{Name: name, Schedule: s})
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		// let a running run finish on shutdown
+		runCtx := context.WithoutCancel(ctx)
+
+		// notify users deferred by working hours once they start working
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := r.RunDeferred(runCtx); err != nil {
+						reportHints(err)
+						log.Printf("Failed to run deferred: %v", err)
+					}
+				}
+			}
+		}()
+
+		// receive actions of users on notifications like slack buttons
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			log.Fatalf("Failed to get listen flag: %v", err)
+		}
+		if listen != "" {
+			handlers, err := r.Handlers()
+			if err != nil {
+				log.Fatalf("Failed to create handlers: %v", err)
+			}
+			mux := http.NewServeMux()
+			for name, h := range handlers {
+				mux.Handle("/interactions/"+name, h)
+				log.Printf("Handle interactions of %s on /interactions/%s", name, name)
+			}
+			srv := &http.Server{Addr: listen, Handler: mux}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatalf("Failed to listen: %v", err)
+				}
+			}()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-ctx.Done()
+				sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				srv.Shutdown(sctx)
+
+				// let actions in progress finish, like running runs
+				for _, h := range handlers {
+					if wh, ok := h.(reviewhub.WaitingHandler); ok {
+						wh.Wait()
+					}
+				}
+			}()
+		}
+
+		log.Printf("Start serving %d scheduled notifiers", len(jobs))
+		err = scheduler.Run(ctx, loc, jobs, func(names []string) {
+			log.Printf("Run notifiers: %v", names)
+			if err := r.RunNotifiers(runCtx, names...); err != nil {
+				reportHints(err)
+				log.Printf("Failed to run: %v", err)
+			}
+		})
+		if err != nil {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+		wg.Wait()
+		log.Printf("Shutdown")
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("listen", "", "address to receive interactions of notifiers like \":8080\", disabled if empty")
+	rootCmd.AddCommand(serveCmd)
+}
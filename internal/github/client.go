@@ -0,0 +1,148 @@
+// Package github is a small GitHub GraphQL API client shared by GitHub based retrievers.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ry023/reviewhub/internal/httpclient"
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+const DefaultEndpoint = "https://api.github.com/graphql"
+
+var httpClient = &httpclient.Client{}
+
+type Client struct {
+	endpoint string
+	token    string
+}
+
+// NewClient returns a client for endpoint, or DefaultEndpoint if empty for GitHub Enterprise
+func NewClient(endpoint, token string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		endpoint: endpoint,
+		token:    token,
+	}
+}
+
+type request struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Query sends query with variables and decodes "data" of the response into out
+func (c *Client) Query(ctx context.Context, query string, variables map[string]any, out any) error {
+	// queries are read only, so safe to retry in spite of POST
+	return c.do(httpclient.Idempotent(ctx), query, variables, out)
+}
+
+// Mutate sends mutation like Query, without retries on server errors not to apply it twice
+func (c *Client) Mutate(ctx context.Context, mutation string, variables map[string]any, out any) error {
+	return c.do(ctx, mutation, variables, out)
+}
+
+func (c *Client) do(ctx context.Context, query string, variables map[string]any, out any) error {
+	b, err := json.Marshal(request{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	body, _, err := httpClient.Do(req)
+	if err != nil {
+		return apiError(err)
+	}
+
+	var res response
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("Invalid GraphQL response: %w", err)
+	}
+	if len(res.Errors) > 0 {
+		// errors come with 200 OK
+		var messages []string
+		for _, e := range res.Errors {
+			messages = append(messages, e.Message)
+		}
+		return &reviewhub.APIError{
+			Service: "GitHub",
+			Code:    res.Errors[0].Type,
+			Message: strings.Join(messages, "; "),
+			Kind:    kindOfType(res.Errors[0].Type),
+		}
+	}
+	if len(res.Data) == 0 || string(res.Data) == "null" {
+		return fmt.Errorf("Invalid GraphQL response: no data")
+	}
+
+	if err := json.Unmarshal(res.Data, out); err != nil {
+		return fmt.Errorf("Invalid GraphQL response: %w", err)
+	}
+	return nil
+}
+
+func kindOfType(typ string) error {
+	switch typ {
+	case "NOT_FOUND":
+		return reviewhub.ErrNotFound
+	case "FORBIDDEN":
+		return reviewhub.ErrForbidden
+	case "RATE_LIMITED":
+		return reviewhub.ErrRateLimited
+	}
+	return reviewhub.ErrGraphQL
+}
+
+// apiError converts error responses to reviewhub.APIError
+func apiError(err error) error {
+	var se *httpclient.StatusError
+	if !errors.As(err, &se) {
+		return err
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(se.Body, &body); err != nil || body.Message == "" {
+		body.Message = string(se.Body)
+	}
+
+	return &reviewhub.APIError{
+		Service:    "GitHub",
+		StatusCode: se.StatusCode,
+		Message:    body.Message,
+		Kind:       reviewhub.KindOfStatus(se.StatusCode),
+	}
+}
+
+type PageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// Actor is a user or a bot, whose login is empty for deleted accounts
+type Actor struct {
+	Login string `json:"login"`
+}
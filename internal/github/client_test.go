@@ -0,0 +1,45 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"message":"Bad credentials"}`, reviewhub.ErrUnauthorized},
+		{"forbidden", http.StatusForbidden, `{"message":"Resource not accessible"}`, reviewhub.ErrForbidden},
+		{"not found", http.StatusNotFound, `{"message":"Not Found"}`, reviewhub.ErrNotFound},
+		{"graphql not found", http.StatusOK, `{"data":null,"errors":[{"type":"NOT_FOUND","message":"Could not resolve"}]}`, reviewhub.ErrNotFound},
+		{"graphql forbidden", http.StatusOK, `{"data":null,"errors":[{"type":"FORBIDDEN","message":"denied"}]}`, reviewhub.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			var out any
+			err := NewClient(srv.URL, "token").Query(context.Background(), "query { viewer { login } }", nil, &out)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Query() error = %v, want %v", err, tt.want)
+			}
+			var e *reviewhub.APIError
+			if !errors.As(err, &e) || e.Service != "GitHub" {
+				t.Errorf("Query() error = %#v, want GitHub APIError", err)
+			}
+		})
+	}
+}
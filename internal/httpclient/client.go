@@ -0,0 +1,271 @@
+// Package httpclient is the http layer shared by API clients of retrievers.
+// It retries rate limited responses with backoff, and server and network errors of idempotent requests,
+// and surfaces non-2xx responses as StatusError.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout    = 60 * time.Second
+	defaultMaxRetries = 5
+	defaultBaseDelay  = time.Second
+	defaultMaxDelay   = 30 * time.Second
+	// give up instead of waiting longer than this for rate limit reset
+	defaultMaxWait = 5 * time.Minute
+)
+
+// Client is safe for concurrent use. Zero values of fields fall back to defaults.
+type Client struct {
+	HTTPClient *http.Client
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	MaxWait    time.Duration
+	// MinInterval throttles requests, like time.Second / 3 for 3 requests per second
+	MinInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// StatusError is returned for non-2xx responses
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	body := string(e.Body)
+	if len(body) > 512 {
+		body = body[:512] + "..."
+	}
+	return fmt.Sprintf("HTTP %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), body)
+}
+
+// Do sends req with retries and returns the response body and header of 2xx response.
+// The body of req must be rewindable by GetBody, which http.NewRequest sets for in-memory readers.
+func (c *Client) Do(req *http.Request) ([]byte, http.Header, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := c.throttle(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		r, err := rewind(req)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		body, header, err := c.do(r)
+		if err == nil {
+			return body, header, nil
+		}
+		if ctx.Err() != nil || attempt >= c.maxRetries() {
+			return nil, nil, err
+		}
+
+		wait, retryable := c.retryWait(r, err, attempt)
+		if !retryable {
+			return nil, nil, err
+		}
+		if wait > c.maxWait() {
+			return nil, nil, fmt.Errorf("rate limited for %s: %w", wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) do(req *http.Request) ([]byte, http.Header, error) {
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+		}
+	}
+	return body, resp.Header, nil
+}
+
+type idempotentKey struct{}
+
+// Idempotent marks requests with the returned context safe to retry on server and network errors,
+// like POST of read only queries
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	v, _ := req.Context().Value(idempotentKey{}).(bool)
+	return v
+}
+
+// retryWait decides whether err is retryable and how long to wait before the next attempt.
+// Server and network errors are retried only for idempotent requests, as the request may have been processed.
+func (c *Client) retryWait(req *http.Request, err error, attempt int) (time.Duration, bool) {
+	var se *StatusError
+	if !errors.As(err, &se) {
+		// network errors
+		if !idempotent(req) {
+			return 0, false
+		}
+		return c.backoff(attempt), true
+	}
+
+	switch {
+	case se.StatusCode == http.StatusTooManyRequests:
+	case se.StatusCode >= 500 && se.StatusCode != http.StatusNotImplemented && idempotent(req):
+	case se.StatusCode == http.StatusForbidden && isRateLimited(se.Header):
+		// GitHub responds 403 for rate limits
+	default:
+		return 0, false
+	}
+
+	if d, ok := retryAfter(se.Header, time.Now()); ok {
+		return d, true
+	}
+	return c.backoff(attempt), true
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.baseDelay() << attempt
+	if d <= 0 || d > c.maxDelay() {
+		d = c.maxDelay()
+	}
+	// full jitter between d/2 and d
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func isRateLimited(h http.Header) bool {
+	return h.Get("Retry-After") != "" || h.Get("X-RateLimit-Remaining") == "0"
+}
+
+// retryAfter reads Retry-After (seconds or http date) or GitHub's X-RateLimit-Reset (unix seconds)
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+
+	return 0, false
+}
+
+// throttle waits until MinInterval passes from the previous request
+func (c *Client) throttle(ctx context.Context) error {
+	if c.MinInterval <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	wait := c.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	c.next = now.Add(wait + c.MinInterval)
+	c.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: defaultTimeout}
+}
+
+func (c *Client) maxRetries() int {
+	if c.MaxRetries > 0 {
+		return c.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+func (c *Client) baseDelay() time.Duration {
+	if c.BaseDelay > 0 {
+		return c.BaseDelay
+	}
+	return defaultBaseDelay
+}
+
+func (c *Client) maxDelay() time.Duration {
+	if c.MaxDelay > 0 {
+		return c.MaxDelay
+	}
+	return defaultMaxDelay
+}
+
+func (c *Client) maxWait() time.Duration {
+	if c.MaxWait > 0 {
+		return c.MaxWait
+	}
+	return defaultMaxWait
+}
@@ -0,0 +1,166 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serve responds statuses in order, repeating the last one, and counts requests
+func serve(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+		status := statuses[min(i, len(statuses)-1)]
+		if status != http.StatusOK {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("body"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func newClient() *Client {
+	return &Client{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		idempotent   bool
+		header       http.Header
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{"success", http.MethodGet, false, nil, []int{200}, 0, 1},
+		{"server error then success", http.MethodGet, false, nil, []int{500, 502, 200}, 0, 3},
+		{"give up after retries", http.MethodGet, false, nil, []int{503}, 503, 4},
+		{"not implemented", http.MethodGet, false, nil, []int{501}, 501, 1},
+		{"rate limited", http.MethodGet, false, http.Header{"Retry-After": {"0"}}, []int{429, 200}, 0, 2},
+		{"github rate limited", http.MethodGet, false, http.Header{"X-Ratelimit-Remaining": {"0"}}, []int{403, 200}, 0, 2},
+		{"unauthorized", http.MethodGet, false, nil, []int{401, 200}, 401, 1},
+		{"forbidden", http.MethodGet, false, nil, []int{403, 200}, 403, 1},
+		{"not found", http.MethodGet, false, nil, []int{404, 200}, 404, 1},
+		{"post server error", http.MethodPost, false, nil, []int{500, 200}, 500, 1},
+		{"patch server error", http.MethodPatch, false, nil, []int{500, 200}, 500, 1},
+		{"post rate limited", http.MethodPost, false, http.Header{"Retry-After": {"0"}}, []int{429, 200}, 0, 2},
+		{"idempotent post server error", http.MethodPost, true, nil, []int{500, 200}, 0, 2},
+		{"put server error", http.MethodPut, false, nil, []int{500, 200}, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, n := serve(t, tt.header, tt.statuses...)
+
+			ctx := context.Background()
+			if tt.idempotent {
+				ctx = Idempotent(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, srv.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, _, err := newClient().Do(req)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				if string(body) != "body" {
+					t.Errorf("Do() body = %q", body)
+				}
+			} else {
+				var se *StatusError
+				if !errors.As(err, &se) || se.StatusCode != tt.wantStatus {
+					t.Fatalf("Do() error = %v, want status %d", err, tt.wantStatus)
+				}
+			}
+			if got := n.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	srv, n := serve(t, http.Header{"Retry-After": {"1"}}, 429, 200)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	start := time.Now()
+	if _, _, err := newClient().Do(req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("retried after %s, want Retry-After 1s", d)
+	}
+	if n.Load() != 2 {
+		t.Errorf("requests = %d, want 2", n.Load())
+	}
+}
+
+func TestDoRetryAfterTooLong(t *testing.T) {
+	srv, n := serve(t, http.Header{"Retry-After": {"3600"}}, 429)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, _, err := newClient().Do(req)
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Do() error = %v, want 429", err)
+	}
+	if n.Load() != 1 {
+		t.Errorf("requests = %d, want 1", n.Load())
+	}
+}
+
+func TestDoNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	tests := []struct {
+		method     string
+		idempotent bool
+		wantTries  int
+	}{
+		{http.MethodGet, false, 4},
+		{http.MethodPost, false, 1},
+		{http.MethodPost, true, 4},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.idempotent {
+			ctx = Idempotent(ctx)
+		}
+		req, _ := http.NewRequestWithContext(ctx, tt.method, url, nil)
+
+		var tries int
+		c := newClient()
+		c.HTTPClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			tries++
+			return http.DefaultTransport.RoundTrip(r)
+		})}
+		if _, _, err := c.Do(req); err == nil {
+			t.Fatalf("%s: Do() error = nil", tt.method)
+		}
+		if tries != tt.wantTries {
+			t.Errorf("%s idempotent=%v: tries = %d, want %d", tt.method, tt.idempotent, tries, tt.wantTries)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
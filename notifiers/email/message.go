@@ -0,0 +1,179 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// buildMessage builds a multipart/alternative mail of plain text and html bodies
+func buildMessage(meta *MetaData, to string, user reviewhub.User, ls []reviewhub.ReviewList, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		render      func(*bytes.Buffer) error
+	}{
+		{"text/plain; charset=UTF-8", func(b *bytes.Buffer) error { return renderText(b, user, ls, now) }},
+		{"text/html; charset=UTF-8", func(b *bytes.Buffer) error { return renderHTML(b, meta.Subject, user, ls, now) }},
+	}
+	for _, p := range parts {
+		var b bytes.Buffer
+		if err := p.render(&b); err != nil {
+			return nil, err
+		}
+
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write(b.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", meta.From},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("UTF-8", meta.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func renderText(b *bytes.Buffer, user reviewhub.User, ls []reviewhub.ReviewList, now time.Time) error {
+	fmt.Fprintf(b, "Hi %s,\r\n", user.Name)
+
+	empty := true
+	for _, l := range ls {
+		if l.Failed() {
+			fmt.Fprintf(b, "\r\nWarning: Failed to retrieve %s, so this list may be incomplete: %s\r\n", l.Name, l.Error)
+		}
+		if len(l.Pages) == 0 {
+			continue
+		}
+		empty = false
+
+		fmt.Fprintf(b, "\r\n%s\r\n", l.Name)
+		for _, p := range l.Pages {
+			title := strings.Join(append([]string{p.Title}, marks(p)...), " ")
+			fmt.Fprintf(b, "- %s (%s)\r\n  %s\r\n", title, description(p, now), p.Url)
+		}
+	}
+	if empty {
+		b.WriteString("\r\nThere are no pages you need to review! Thank you for your cooperation!\r\n")
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("mail").Parse(`<html>
+<body>
+<h2>{{.Title}}</h2>
+<p>Hi {{.User}},</p>
+{{- range .Lists}}
+{{- if .Error}}
+<p><em>Warning: Failed to retrieve {{.Name}}, so this list may be incomplete: {{.Error}}</em></p>
+{{- end}}
+{{- if .Pages}}
+<h3>{{.Name}}</h3>
+<ul>
+{{- range .Pages}}
+<li><a href="{{.Url}}"><strong>{{.Title}}</strong></a>{{range .Marks}} <strong>{{.}}</strong>{{end}} ({{.Description}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- if .Empty}}
+<p>There are no pages you need to review! Thank you for your cooperation!</p>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlPage struct {
+	Title       string
+	Url         string
+	Marks       []string
+	Description string
+}
+
+type htmlList struct {
+	Name  string
+	Error string
+	Pages []htmlPage
+}
+
+func renderHTML(b *bytes.Buffer, title string, user reviewhub.User, ls []reviewhub.ReviewList, now time.Time) error {
+	data := struct {
+		Title string
+		User  string
+		Lists []htmlList
+		Empty bool
+	}{
+		Title: title,
+		User:  user.Name,
+		Empty: true,
+	}
+	for _, l := range ls {
+		hl := htmlList{Name: l.Name, Error: l.Error}
+		for _, p := range l.Pages {
+			hl.Pages = append(hl.Pages, htmlPage{
+				Title:       p.Title,
+				Url:         p.Url,
+				Marks:       marks(p),
+				Description: description(p, now),
+			})
+			data.Empty = false
+		}
+		data.Lists = append(data.Lists, hl)
+	}
+	return htmlTemplate.Execute(b, data)
+}
+
+func description(p reviewhub.ReviewPage, now time.Time) string {
+	if p.Since().IsZero() {
+		return fmt.Sprintf("by %s", p.Owner.Name)
+	}
+	return fmt.Sprintf("by %s, waiting %s", p.Owner.Name, reviewhub.FormatAge(now.Sub(p.Since())))
+}
+
+func marks(p reviewhub.ReviewPage) []string {
+	var m []string
+	if p.IsNew {
+		m = append(m, "[NEW]")
+	}
+	switch p.SLAStatus {
+	case reviewhub.SLAWarning:
+		m = append(m, "[SLA warning]")
+	case reviewhub.SLAEscalated:
+		m = append(m, "[SLA escalated]")
+	}
+	return m
+}
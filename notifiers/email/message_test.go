@@ -0,0 +1,80 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestBuildMessage(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	meta := &MetaData{From: "reviewhub@example.com", Subject: "Reviews <today> ✅"}
+	ls := []reviewhub.ReviewList{
+		{
+			Name: "docs & specs",
+			Pages: []reviewhub.ReviewPage{{
+				Page: reviewhub.Page{
+					Title:     `<script>alert("x")</script>`,
+					Url:       `https://example.com/?a=1&b="2"`,
+					Owner:     reviewhub.User{Name: "bob"},
+					CreatedAt: now.Add(-3 * time.Hour),
+				},
+				IsNew: true,
+			}},
+		},
+		{Name: "broken", Error: "<b>timeout</b>"},
+	}
+
+	b, err := buildMessage(meta, "alice@example.com", reviewhub.User{Name: "alice"}, ls, now)
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+	parts := readParts(t, b)
+
+	html := parts["text/html"]
+	for _, want := range []string{
+		"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;",
+		"docs &amp; specs",
+		`href="https://example.com/?a=1&amp;b=%222%22"`,
+		"&lt;b&gt;timeout&lt;/b&gt;",
+		"Reviews &lt;today&gt;",
+		"[NEW]",
+		"by bob, waiting",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part lacks %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") || strings.Contains(html, "<b>") {
+		t.Errorf("html part not escaped:\n%s", html)
+	}
+
+	text := parts["text/plain"]
+	for _, want := range []string{`- <script>alert("x")</script> [NEW] (by bob, waiting`, "Warning: Failed to retrieve broken", "docs & specs"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text part lacks %q:\n%s", want, text)
+		}
+	}
+
+	header := string(b[:strings.Index(string(b), "\r\n\r\n")])
+	for _, want := range []string{"From: reviewhub@example.com", "To: alice@example.com", "Subject: =?UTF-8?q?", "MIME-Version: 1.0"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header lacks %q:\n%s", want, header)
+		}
+	}
+}
+
+func TestBuildMessageVoid(t *testing.T) {
+	meta := &MetaData{From: "reviewhub@example.com", Subject: "Pending Reviews"}
+	b, err := buildMessage(meta, "alice@example.com", reviewhub.User{Name: "alice"}, []reviewhub.ReviewList{{Name: "docs"}}, time.Now())
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+	for typ, body := range readParts(t, b) {
+		if !strings.Contains(body, "There are no pages you need to review!") || strings.Contains(body, "docs") {
+			t.Errorf("%s part = %q, want only void message", typ, body)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+const (
+	// TLSStartTLS upgrades the connection by STARTTLS, usually on port 587
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects over TLS from the start, usually on port 465
+	TLSImplicit = "tls"
+	// TLSNone sends in plain text, only for local relays
+	TLSNone = "none"
+)
+
+// defaultTimeout bounds a delivery if ctx has no deadline, not to hang on unresponsive servers
+const defaultTimeout = 60 * time.Second
+
+type EmailNotifier struct {
+}
+
+func init() {
+	reviewhub.RegisterNotifier("email", func() reviewhub.Notifier { return new(EmailNotifier) })
+}
+
+type MetaData struct {
+	Host string `yaml:"host" validate:"required"`
+	Port int    `yaml:"port"`
+	TLS  string `yaml:"tls"`
+	// InsecureSkipVerify skips verifying the certificate of the server
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+	// Authenticates by PLAIN if set
+	UsernameEnv string `yaml:"username_env"`
+	PasswordEnv string `yaml:"password_env"`
+
+	From    string `yaml:"from" validate:"required"`
+	Subject string `yaml:"subject"`
+	// SendIfVoid sends mails even if users have no pages to review
+	SendIfVoid bool `yaml:"send_if_void"`
+}
+
+func (m *MetaData) Validate() error {
+	switch m.TLS {
+	case "":
+		m.TLS = TLSStartTLS
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return fmt.Errorf("Invalid tls: %s", m.TLS)
+	}
+	if m.Port == 0 {
+		if m.TLS == TLSImplicit {
+			m.Port = 465
+		} else {
+			m.Port = 587
+		}
+	}
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("Invalid from: %w", err)
+	}
+	if m.Subject == "" {
+		m.Subject = "Pending Reviews"
+	}
+	return nil
+}
+
+type UserMetaData struct {
+	Email string `yaml:"email" validate:"required"`
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if err := meta.Validate(); err != nil {
+		return err
+	}
+
+	usermeta, err := reviewhub.ParseMetaData[UserMetaData](user.MetaData)
+	if err != nil {
+		// user metadata not satisfied
+		return nil
+	}
+
+	if !meta.SendIfVoid && isVoid(ls) {
+		return nil
+	}
+
+	msg, err := buildMessage(meta, usermeta.Email, user, ls, time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed to build mail: %w", err)
+	}
+	if err := send(ctx, meta, usermeta.Email, msg); err != nil {
+		return fmt.Errorf("Failed to send mail to %s: %w", usermeta.Email, err)
+	}
+	return nil
+}
+
+func isVoid(ls []reviewhub.ReviewList) bool {
+	for _, l := range ls {
+		if len(l.Pages) > 0 || l.Failed() {
+			return false
+		}
+	}
+	return true
+}
+
+// send delivers msg to the address by SMTP
+func send(ctx context.Context, meta *MetaData, to string, msg []byte) error {
+	// from may have a display name for the header, but the envelope takes the address only
+	from, err := mail.ParseAddress(meta.From)
+	if err != nil {
+		return fmt.Errorf("Invalid from: %w", err)
+	}
+	addr := net.JoinHostPort(meta.Host, strconv.Itoa(meta.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         meta.Host,
+		InsecureSkipVerify: meta.InsecureSkipVerify,
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(deadline)
+	if meta.TLS == TLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, meta.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if meta.TLS == TLSStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("Failed to start tls: %w", err)
+		}
+	}
+	if meta.UsernameEnv != "" {
+		auth := smtp.PlainAuth("", os.Getenv(meta.UsernameEnv), os.Getenv(meta.PasswordEnv), meta.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("Failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,324 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// smtpStub is a minimal SMTP server recording commands and the mail data
+type smtpStub struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	implicit  bool
+	startTLS  bool
+	auth      bool
+
+	mu       sync.Mutex
+	conns    int
+	commands []string
+	data     []byte
+}
+
+func newSMTPStub(t *testing.T, implicit, startTLS, auth bool) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStub{
+		ln:        ln,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}},
+		implicit:  implicit,
+		startTLS:  startTLS,
+		auth:      auth,
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStub) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) record(cmd string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, cmd)
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.conns++
+	s.mu.Unlock()
+
+	secure := s.implicit
+	if s.implicit {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 stub ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		s.record(line)
+
+		verb, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			exts := []string{"stub"}
+			if s.startTLS && !secure {
+				exts = append(exts, "STARTTLS")
+			}
+			if s.auth {
+				exts = append(exts, "AUTH PLAIN")
+			}
+			for i, e := range exts {
+				sep := "-"
+				if i == len(exts)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			conn = tls.Server(conn, s.tlsConfig)
+			tp = textproto.NewConn(conn)
+			secure = true
+		case "AUTH":
+			if line == "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00user\x00pass")) {
+				tp.PrintfLine("235 ok")
+			} else {
+				tp.PrintfLine("535 invalid credentials")
+			}
+		case "MAIL", "RCPT":
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = data
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func testConfig(s *smtpStub, tlsMode string, auth bool, sendIfVoid bool) reviewhub.NotifierConfig {
+	meta := map[any]any{
+		"host":                 "127.0.0.1",
+		"port":                 s.port(),
+		"tls":                  tlsMode,
+		"insecure_skip_verify": true,
+		"from":                 "Reviewhub <reviewhub@example.com>",
+		"send_if_void":         sendIfVoid,
+	}
+	if auth {
+		meta["username_env"] = "TEST_SMTP_USER"
+		meta["password_env"] = "TEST_SMTP_PASS"
+	}
+	return reviewhub.NotifierConfig{Name: "mail", Type: "email", MetaData: meta}
+}
+
+var (
+	alice = reviewhub.User{Name: "alice", MetaData: map[any]any{"email": "alice@example.com"}}
+	lists = []reviewhub.ReviewList{{
+		Name: "docs",
+		Pages: []reviewhub.ReviewPage{{
+			Page: reviewhub.Page{Title: "Design doc", Url: "https://example.com/doc", Owner: reviewhub.User{Name: "bob"}},
+		}},
+	}}
+)
+
+func TestNotify(t *testing.T) {
+	tests := []struct {
+		name      string
+		tls       string
+		auth      bool
+		wantStart []string
+	}{
+		{"none", TLSNone, false, []string{"EHLO localhost"}},
+		{"none with auth", TLSNone, true, []string{"EHLO localhost", "AUTH PLAIN AHVzZXIAcGFzcw=="}},
+		{"starttls", TLSStartTLS, false, []string{"EHLO localhost", "STARTTLS", "EHLO localhost"}},
+		{"starttls with auth", TLSStartTLS, true, []string{"EHLO localhost", "STARTTLS", "EHLO localhost", "AUTH PLAIN AHVzZXIAcGFzcw=="}},
+		{"implicit tls", TLSImplicit, false, []string{"EHLO localhost"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SMTP_USER", "user")
+			t.Setenv("TEST_SMTP_PASS", "pass")
+			s := newSMTPStub(t, tt.tls == TLSImplicit, tt.tls == TLSStartTLS, tt.auth)
+
+			if err := new(EmailNotifier).Notify(context.Background(), testConfig(s, tt.tls, tt.auth, false), alice, lists); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			want := append(tt.wantStart, "MAIL FROM:<reviewhub@example.com>", "RCPT TO:<alice@example.com>", "DATA", "QUIT")
+			if !slices.Equal(s.commands, want) {
+				t.Errorf("commands = %q, want %q", s.commands, want)
+			}
+
+			if !strings.Contains(string(s.data), "From: Reviewhub <reviewhub@example.com>\n") {
+				t.Errorf("From header lacks the display name:\n%s", s.data)
+			}
+			parts := readParts(t, s.data)
+			for _, typ := range []string{"text/plain", "text/html"} {
+				if !strings.Contains(parts[typ], "Design doc") || !strings.Contains(parts[typ], "https://example.com/doc") {
+					t.Errorf("%s part = %q", typ, parts[typ])
+				}
+			}
+		})
+	}
+}
+
+func TestNotifySendIfVoid(t *testing.T) {
+	void := []reviewhub.ReviewList{{Name: "docs"}}
+
+	for _, sendIfVoid := range []bool{false, true} {
+		s := newSMTPStub(t, false, false, false)
+		if err := new(EmailNotifier).Notify(context.Background(), testConfig(s, TLSNone, false, sendIfVoid), alice, void); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+
+		s.mu.Lock()
+		conns, data := s.conns, s.data
+		s.mu.Unlock()
+		if !sendIfVoid {
+			if conns != 0 {
+				t.Errorf("connected %d times without send_if_void", conns)
+			}
+			continue
+		}
+		parts := readParts(t, data)
+		for typ, body := range parts {
+			if !strings.Contains(body, "There are no pages you need to review!") {
+				t.Errorf("%s part = %q, want void message", typ, body)
+			}
+		}
+	}
+}
+
+func TestNotifyTimeout(t *testing.T) {
+	// accepts but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	meta := &MetaData{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, TLS: TLSNone, From: "reviewhub@example.com"}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := send(ctx, meta, "alice@example.com", []byte("x")); err == nil {
+		t.Error("send() error = nil for unresponsive server")
+	}
+}
+
+// readParts parses the multipart/alternative message into decoded bodies by content type
+func readParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid message: %v", err)
+	}
+	typ, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || typ != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+
+	parts := map[string]string{}
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Invalid part: %v", err)
+		}
+		// quoted-printable is decoded by the reader
+		b, err := io.ReadAll(bufio.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		types = append(types, pt)
+		parts[pt] = string(b)
+	}
+	if !slices.Equal(types, []string{"text/plain", "text/html"}) {
+		t.Fatalf("parts = %v, want text/plain and text/html", types)
+	}
+	return parts
+}
@@ -0,0 +1,157 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+const (
+	FormatJson     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// ReportNotifier writes pending reviews of all users into a single file per run.
+// The file can be published as an artifact or a page of GitHub Actions.
+type ReportNotifier struct {
+}
+
+func init() {
+	reviewhub.RegisterNotifier("report", func() reviewhub.Notifier { return new(ReportNotifier) })
+}
+
+type MetaData struct {
+	Path   string `yaml:"path" validate:"required"`
+	Format string `yaml:"format"`
+	Title  string `yaml:"title"`
+}
+
+func (m *MetaData) Validate() error {
+	switch m.Format {
+	case "":
+		m.Format = FormatMarkdown
+	case FormatJson, FormatMarkdown:
+	default:
+		return fmt.Errorf("Invalid format type: %s", m.Format)
+	}
+	if m.Title == "" {
+		m.Title = "Pending Reviews"
+	}
+	return nil
+}
+
+func (n *ReportNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+	return n.NotifyAll(ctx, config, []reviewhub.Notification{{User: user, ReviewLists: ls}})
+}
+
+// Batch is always true, as the report is a file for all users
+func (n *ReportNotifier) Batch(config reviewhub.NotifierConfig) bool {
+	return true
+}
+
+func (n *ReportNotifier) NotifyAll(ctx context.Context, config reviewhub.NotifierConfig, ns []reviewhub.Notification) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if err := meta.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var b []byte
+	switch meta.Format {
+	case FormatJson:
+		b, err = json.MarshalIndent(report{
+			Title:         meta.Title,
+			GeneratedAt:   now,
+			Notifications: ns,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+	case FormatMarkdown:
+		b = markdown(meta.Title, ns, now)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(meta.Path), 0o755); err != nil {
+		return err
+	}
+
+	// write to temporary file and rename it not to leave a broken report on failure
+	tmp := meta.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("Failed to write report: %w", err)
+	}
+	return os.Rename(tmp, meta.Path)
+}
+
+type report struct {
+	Title         string
+	GeneratedAt   time.Time
+	Notifications []reviewhub.Notification
+}
+
+func markdown(title string, ns []reviewhub.Notification, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\nGenerated at %s\n", title, now.Format(time.RFC3339))
+
+	// failures are shared by users, so show them once
+	failed := map[string]bool{}
+	for _, v := range ns {
+		for _, l := range v.ReviewLists {
+			if l.Failed() && !failed[l.Name] {
+				failed[l.Name] = true
+				fmt.Fprintf(&b, "\n> **Warning**: Failed to retrieve %s, so this report may be incomplete: %s\n", l.Name, l.Error)
+			}
+		}
+	}
+
+	for _, v := range ns {
+		fmt.Fprintf(&b, "\n## %s\n", v.User.Name)
+
+		empty := true
+		for _, l := range v.ReviewLists {
+			if len(l.Pages) == 0 {
+				continue
+			}
+			empty = false
+
+			fmt.Fprintf(&b, "\n### %s\n\n", l.Name)
+			for _, p := range l.Pages {
+				fmt.Fprintf(&b, "- [%s](%s) %s%s\n", p.Title, p.Url, description(p, now), marks(p))
+			}
+		}
+		if empty {
+			b.WriteString("\nNo pending reviews.\n")
+		}
+	}
+	return b.Bytes()
+}
+
+func description(p reviewhub.ReviewPage, now time.Time) string {
+	if p.Since().IsZero() {
+		return fmt.Sprintf("by %s", p.Owner.Name)
+	}
+	return fmt.Sprintf("by %s, waiting %s", p.Owner.Name, reviewhub.FormatAge(now.Sub(p.Since())))
+}
+
+func marks(p reviewhub.ReviewPage) string {
+	var m string
+	if p.IsNew {
+		m += " **NEW**"
+	}
+	switch p.SLAStatus {
+	case reviewhub.SLAWarning:
+		m += " (SLA warning)"
+	case reviewhub.SLAEscalated:
+		m += " **(SLA escalated)**"
+	}
+	return m
+}
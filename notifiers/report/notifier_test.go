@@ -0,0 +1,115 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+var now = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+func notifications() []reviewhub.Notification {
+	failed := reviewhub.ReviewList{Name: "gitlab", Error: "401 Unauthorized"}
+	page := reviewhub.ReviewPage{
+		Page: reviewhub.Page{
+			Title:     "Fix login",
+			Url:       "https://github.com/o/r/pull/1",
+			Owner:     reviewhub.User{Name: "erin"},
+			CreatedAt: now.Add(-50 * time.Hour),
+		},
+		IsNew:     true,
+		SLAStatus: reviewhub.SLAEscalated,
+	}
+	return []reviewhub.Notification{
+		{
+			User: reviewhub.User{Name: "alice"},
+			ReviewLists: []reviewhub.ReviewList{
+				{Name: "github", Pages: []reviewhub.ReviewPage{page}},
+				failed,
+			},
+		},
+		{
+			User:        reviewhub.User{Name: "bob"},
+			ReviewLists: []reviewhub.ReviewList{{Name: "github"}, failed},
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	got := string(markdown("Pending Reviews", notifications(), now))
+
+	for _, want := range []string{
+		"# Pending Reviews\n\nGenerated at 2026-10-18T09:00:00Z\n",
+		"## alice\n\n### github\n\n- [Fix login](https://github.com/o/r/pull/1) by erin, waiting 2d **NEW** **(SLA escalated)**\n",
+		"## bob\n\nNo pending reviews.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, got)
+		}
+	}
+	// failures are shared by users
+	if n := strings.Count(got, "Failed to retrieve gitlab"); n != 1 {
+		t.Errorf("failure of gitlab shown %d times, want once:\n%s", n, got)
+	}
+}
+
+func TestNotifyAll(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"", false},
+		{FormatMarkdown, false},
+		{FormatJson, false},
+		{"html", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out", "report")
+			config := reviewhub.NotifierConfig{
+				Name:     "report",
+				Type:     "report",
+				MetaData: map[any]any{"path": path, "format": tt.format},
+			}
+
+			err := new(ReportNotifier).NotifyAll(context.Background(), config, notifications())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NotifyAll() error = nil, want invalid format")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NotifyAll() error = %v", err)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Report not written: %v", err)
+			}
+			if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("Temporary file left: %v", err)
+			}
+
+			if tt.format != FormatJson {
+				if !strings.HasPrefix(string(b), "# Pending Reviews\n") {
+					t.Errorf("Report is not markdown:\n%s", b)
+				}
+				return
+			}
+			var r report
+			if err := json.Unmarshal(b, &r); err != nil {
+				t.Fatalf("Report is not json: %v", err)
+			}
+			if r.Title != "Pending Reviews" || len(r.Notifications) != 2 || r.Notifications[0].User.Name != "alice" {
+				t.Errorf("Unexpected report: %+v", r)
+			}
+		})
+	}
+}
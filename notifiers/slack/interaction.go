@@ -0,0 +1,215 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+	"github.com/slack-go/slack"
+)
+
+const (
+	actionMarkReviewed = "reviewhub_mark_reviewed"
+	actionSnooze       = "reviewhub_snooze"
+	actionOpen         = "reviewhub_open"
+
+	snoozeDuration = 24 * time.Hour
+	// actions may wait for a run holding the state, and retry requests to sources
+	actionTimeout = 15 * time.Minute
+)
+
+// actionValue identifies the page of buttons and the reviewer they are for
+type actionValue struct {
+	List     string `json:"l"`
+	Url      string `json:"u"`
+	ID       string `json:"i,omitempty"`
+	Reviewer string `json:"r,omitempty"`
+}
+
+// buildActionBlock builds buttons of the page for the reviewer, marking reviewed only if the list is approvable
+func buildActionBlock(list string, page reviewhub.ReviewPage, reviewer string, approvable bool) slack.Block {
+	b, _ := json.Marshal(actionValue{List: list, Url: page.Url, ID: page.ID, Reviewer: reviewer})
+	value := string(b)
+
+	var els []slack.BlockElement
+	if approvable {
+		els = append(els, slack.NewButtonBlockElement(actionMarkReviewed, value,
+			slack.NewTextBlockObject(slack.PlainTextType, "Mark reviewed", false, false),
+		).WithStyle(slack.StylePrimary))
+	}
+	els = append(els,
+		slack.NewButtonBlockElement(actionSnooze, value,
+			slack.NewTextBlockObject(slack.PlainTextType, "Snooze 1 day", false, false),
+		),
+		slack.NewButtonBlockElement(actionOpen, value,
+			slack.NewTextBlockObject(slack.PlainTextType, "Open", false, false),
+		).WithURL(page.Url),
+	)
+	return slack.NewActionBlock("", els...)
+}
+
+// Handler handles interactivity payloads of buttons on pages if interactive
+func (n *SlackNotifier) Handler(config reviewhub.NotifierConfig, users []reviewhub.User, actions reviewhub.Actions) (http.Handler, error) {
+	meta, err := parseMetaData(config)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.Interactive {
+		return nil, nil
+	}
+
+	secret := os.Getenv(meta.SigningSecretEnv)
+	if secret == "" {
+		// requests could not be verified
+		return nil, fmt.Errorf("Signing secret not set in %s", meta.SigningSecretEnv)
+	}
+
+	return &interactionHandler{
+		signingSecret: secret,
+		users:         users,
+		actions:       actions,
+	}, nil
+}
+
+type interactionHandler struct {
+	signingSecret string
+	users         []reviewhub.User
+	actions       reviewhub.Actions
+
+	// wg tracks actions performed after responding
+	wg sync.WaitGroup
+}
+
+// Wait waits for actions in progress, after the server stopped accepting requests
+func (h *interactionHandler) Wait() {
+	h.wg.Wait()
+}
+
+func (h *interactionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	// https://api.slack.com/authentication/verifying-requests-from-slack
+	sv, err := slack.NewSecretsVerifier(req.Header, h.signingSecret)
+	if err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	sv.Write(body)
+	if err := sv.Ensure(); err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	var cb slack.InteractionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &cb); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	if cb.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	// Slack requires the response in 3 seconds, so actions are performed after and responded by response_url
+	w.WriteHeader(http.StatusOK)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.handle(cb)
+	}()
+}
+
+// handle performs actions of the callback and responds results to the user
+func (h *interactionHandler) handle(cb slack.InteractionCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+
+	user := h.findUser(cb.User.ID)
+	for _, a := range cb.ActionCallback.BlockActions {
+		var text string
+		if user == nil {
+			text = "You are not a user of reviewhub."
+		} else {
+			var err error
+			text, err = h.perform(ctx, a, *user)
+			if err != nil {
+				log.Printf("Failed to perform %s by %s: %v", a.ActionID, user.Name, err)
+				text = fmt.Sprintf("Failed: %v", err)
+			}
+		}
+		if text == "" || cb.ResponseURL == "" {
+			continue
+		}
+
+		msg := &slack.WebhookMessage{Text: text, ResponseType: slack.ResponseTypeEphemeral}
+		if err := slack.PostWebhookContext(ctx, cb.ResponseURL, msg); err != nil {
+			log.Printf("Failed to respond to %s: %v", cb.User.ID, err)
+		}
+	}
+}
+
+// perform runs the action and returns the text to respond
+func (h *interactionHandler) perform(ctx context.Context, a *slack.BlockAction, user reviewhub.User) (string, error) {
+	if a.ActionID == actionOpen {
+		// opened by the url of the button
+		return "", nil
+	}
+
+	var v actionValue
+	if err := json.Unmarshal([]byte(a.Value), &v); err != nil {
+		return "", fmt.Errorf("Invalid action value: %w", err)
+	}
+	page := reviewhub.Page{ID: v.ID, Url: v.Url}
+
+	switch a.ActionID {
+	case actionMarkReviewed:
+		// buttons are seen by others in channels, but only the reviewer approves
+		if v.Reviewer != user.Name {
+			return fmt.Sprintf("Only %s can mark this as reviewed.", v.Reviewer), nil
+		}
+		if err := h.actions.MarkReviewed(ctx, v.List, page, user); err != nil {
+			return "", err
+		}
+		log.Printf("%s marked %s as reviewed", user.Name, v.Url)
+		return fmt.Sprintf("Marked %s as reviewed.", v.Url), nil
+	case actionSnooze:
+		until := time.Now().Add(snoozeDuration)
+		if err := h.actions.Snooze(ctx, page, user, until); err != nil {
+			return "", err
+		}
+		log.Printf("%s snoozed %s", user.Name, v.Url)
+		return fmt.Sprintf("Snoozed %s until %s.", v.Url, until.Format(time.RFC1123)), nil
+	default:
+		return "", fmt.Errorf("Unknown action: %s", a.ActionID)
+	}
+}
+
+// findUser searches users by slack_id
+func (h *interactionHandler) findUser(slackId string) *reviewhub.User {
+	for _, u := range h.users {
+		umeta, err := reviewhub.ParseMetaData[UserMetaData](u.MetaData)
+		if err != nil {
+			continue
+		}
+		if umeta.SlackId == slackId {
+			return &u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+	"github.com/slack-go/slack"
+)
+
+const testSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// payload is a block_actions payload recorded from Slack, with the action and response url replaced
+const payload = `{
+  "type": "block_actions",
+  "user": {"id": "%s", "username": "alice", "name": "alice", "team_id": "T0CAG"},
+  "api_app_id": "A0CA5",
+  "token": "Shh_its_a_seekrit",
+  "container": {"type": "message", "message_ts": "1548261231.000200", "channel_id": "C0CA7", "is_ephemeral": false},
+  "trigger_id": "12466734323.1395872398",
+  "team": {"id": "T0CAG", "domain": "acme-creamery"},
+  "channel": {"id": "C0CA7", "name": "reviews"},
+  "response_url": "%s",
+  "actions": [
+    {
+      "action_id": "%s",
+      "block_id": "3dQ1",
+      "text": {"type": "plain_text", "text": "Button", "emoji": true},
+      "value": "{\"l\":\"notion\",\"u\":\"https://www.notion.so/page-1\",\"i\":\"page-1\",\"r\":\"alice\"}",
+      "type": "button",
+      "action_ts": "1548426417.840180"
+    }
+  ]
+}`
+
+type call struct {
+	action string
+	list   string
+	page   reviewhub.Page
+	user   string
+	until  time.Time
+}
+
+type fakeActions struct {
+	calls chan call
+}
+
+func (a *fakeActions) MarkReviewed(ctx context.Context, list string, page reviewhub.Page, user reviewhub.User) error {
+	a.calls <- call{action: "mark_reviewed", list: list, page: page, user: user.Name}
+	return nil
+}
+
+func (a *fakeActions) Snooze(ctx context.Context, page reviewhub.Page, user reviewhub.User, until time.Time) error {
+	a.calls <- call{action: "snooze", page: page, user: user.Name, until: until}
+	return nil
+}
+
+func signedRequest(t *testing.T, secret, body string) *http.Request {
+	t.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestInteractionHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		slackId    string
+		actionId   string
+		wantStatus int
+		wantCall   *call
+		wantText   string
+	}{
+		{
+			name: "bad signature", secret: "wrong", slackId: "U1", actionId: actionMarkReviewed,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "mark reviewed", secret: testSecret, slackId: "U1", actionId: actionMarkReviewed,
+			wantStatus: http.StatusOK,
+			wantCall:   &call{action: "mark_reviewed", list: "notion", page: reviewhub.Page{ID: "page-1", Url: "https://www.notion.so/page-1"}, user: "alice"},
+			wantText:   "Marked https://www.notion.so/page-1 as reviewed.",
+		},
+		{
+			// e.g. bob clicking the button of alice in a channel digest
+			name: "mark reviewed by others", secret: testSecret, slackId: "U2", actionId: actionMarkReviewed,
+			wantStatus: http.StatusOK,
+			wantText:   "Only alice can mark this as reviewed.",
+		},
+		{
+			name: "snooze", secret: testSecret, slackId: "U1", actionId: actionSnooze,
+			wantStatus: http.StatusOK,
+			wantCall:   &call{action: "snooze", page: reviewhub.Page{ID: "page-1", Url: "https://www.notion.so/page-1"}, user: "alice"},
+			wantText:   "Snoozed https://www.notion.so/page-1 until ",
+		},
+		{
+			name: "unknown user", secret: testSecret, slackId: "U9", actionId: actionMarkReviewed,
+			wantStatus: http.StatusOK,
+			wantText:   "You are not a user of reviewhub.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make(chan slack.WebhookMessage, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg slack.WebhookMessage
+				if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+					t.Errorf("Invalid response message: %v", err)
+				}
+				responses <- msg
+			}))
+			defer srv.Close()
+
+			actions := &fakeActions{calls: make(chan call, 1)}
+			h := &interactionHandler{
+				signingSecret: testSecret,
+				users: []reviewhub.User{
+					{Name: "alice", MetaData: map[any]any{"slack_id": "U1"}},
+					{Name: "bob", MetaData: map[any]any{"slack_id": "U2"}},
+				},
+				actions: actions,
+			}
+
+			body := url.Values{"payload": {fmt.Sprintf(payload, tt.slackId, srv.URL, tt.actionId)}}.Encode()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, signedRequest(t, tt.secret, body))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			h.Wait()
+
+			select {
+			case msg := <-responses:
+				if !strings.HasPrefix(msg.Text, tt.wantText) || msg.ResponseType != slack.ResponseTypeEphemeral {
+					t.Errorf("response = %+v, want %q", msg, tt.wantText)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no response to response_url")
+			}
+
+			select {
+			case c := <-actions.calls:
+				if tt.wantCall == nil {
+					t.Fatalf("unexpected action %+v", c)
+				}
+				if c.action == "snooze" {
+					if d := time.Until(c.until); d < snoozeDuration-time.Minute || d > snoozeDuration {
+						t.Errorf("snoozed until %v", c.until)
+					}
+					c.until = time.Time{}
+				}
+				if c != *tt.wantCall {
+					t.Errorf("action = %+v, want %+v", c, *tt.wantCall)
+				}
+			default:
+				if tt.wantCall != nil {
+					t.Errorf("action not performed, want %+v", *tt.wantCall)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerRequiresSecret(t *testing.T) {
+	config := reviewhub.NotifierConfig{
+		Name: "slack",
+		Type: "slack",
+		MetaData: map[any]any{
+			"api_token_env":      "SLACK_TOKEN",
+			"channel":            "#reviews",
+			"interactive":        true,
+			"signing_secret_env": "TEST_SLACK_SIGNING_SECRET",
+		},
+	}
+
+	t.Setenv("TEST_SLACK_SIGNING_SECRET", "")
+	if _, err := new(SlackNotifier).Handler(config, nil, &fakeActions{}); err == nil {
+		t.Error("Handler() error = nil for empty signing secret")
+	}
+
+	t.Setenv("TEST_SLACK_SIGNING_SECRET", testSecret)
+	if h, err := new(SlackNotifier).Handler(config, nil, &fakeActions{}); err != nil || h == nil {
+		t.Errorf("Handler() = %v, %v", h, err)
+	}
+}
+
+func TestBuildPageListBlocksButtons(t *testing.T) {
+	var pages []reviewhub.ReviewPage
+	for i := 0; i < 15; i++ {
+		pages = append(pages, reviewhub.ReviewPage{Page: reviewhub.Page{Title: fmt.Sprint(i), Url: fmt.Sprint("https://example.com/", i)}})
+	}
+	l := reviewhub.ReviewList{Name: "notion", Pages: pages}
+
+	buttons := func(b []slack.Block) (actions int, markReviewed int) {
+		for _, v := range b {
+			a, ok := v.(*slack.ActionBlock)
+			if !ok {
+				continue
+			}
+			actions++
+			for _, e := range a.Elements.ElementSet {
+				if e.(*slack.ButtonBlockElement).ActionID == actionMarkReviewed {
+					markReviewed++
+				}
+			}
+		}
+		return
+	}
+
+	tests := []struct {
+		name             string
+		meta             *MetaData
+		wantBlocks       int
+		wantActions      int
+		wantMarkReviewed int
+	}{
+		{"not interactive", nil, 1, 0, 0},
+		{"interactive", &MetaData{Interactive: true}, 1 + 2*maxButtonPages + 1, maxButtonPages, 0},
+		{"approvable", &MetaData{Interactive: true, approvable: []string{"notion"}}, 1 + 2*maxButtonPages + 1, maxButtonPages, maxButtonPages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := buildPageListBlocks(l, tt.meta, "alice")
+			actions, markReviewed := buttons(b)
+			if len(b) != tt.wantBlocks || actions != tt.wantActions || markReviewed != tt.wantMarkReviewed {
+				t.Errorf("blocks, actions, mark reviewed = %d, %d, %d, want %d, %d, %d",
+					len(b), actions, markReviewed, tt.wantBlocks, tt.wantActions, tt.wantMarkReviewed)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package slack
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/ry023/reviewhub/reviewhub"
 	"github.com/slack-go/slack"
@@ -14,37 +18,154 @@ type SlackNotifier struct {
 	ApiToken string
 }
 
+func init() {
+	reviewhub.RegisterNotifier("slack", func() reviewhub.Notifier { return new(SlackNotifier) })
+}
+
+const (
+	// ModeEphemeral posts ephemeral messages to each user in the channel
+	ModeEphemeral = "ephemeral"
+	// ModeDM posts direct messages to each user
+	ModeDM = "dm"
+	// ModeChannelDigest posts one message to the channel summarizing all users with mentions
+	ModeChannelDigest = "channel_digest"
+)
+
+const (
+	// PreviousMessageUpdate updates the message posted earlier in the day
+	PreviousMessageUpdate = "update"
+	// PreviousMessageReply replies in the thread of the message posted earlier in the day
+	PreviousMessageReply = "reply"
+)
+
 type MetaData struct {
 	ApiTokenEnv   string `yaml:"api_token_env" validate:"required"`
-	Channel       string `yaml:"channel" validate:"required"`
+	Mode          string `yaml:"mode"`
+	Channel       string `yaml:"channel"`
 	Title         string `yaml:"title"`
 	MessageIfVoid string `yaml:"message_if_void"`
+
+	// Interactive adds buttons to pages, which needs `serve --listen` and the request URL set in the Slack app
+	Interactive      bool   `yaml:"interactive"`
+	SigningSecretEnv string `yaml:"signing_secret_env"`
+
+	// PreviousMessage reuses the message of dm or channel_digest instead of posting new one every run, requires state
+	PreviousMessage string `yaml:"previous_message"`
+
+	// approvable is names of lists to show the button marking reviewed
+	approvable []string
+	// location to tell days of previous messages
+	location *time.Location
 }
 
 type UserMetaData struct {
 	SlackId string `yaml:"slack_id" validate:"required"`
 }
 
-func (n *SlackNotifier) Notify(config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+func parseMetaData(config reviewhub.NotifierConfig) (*MetaData, error) {
 	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return nil, err
+	}
+
+	switch meta.Mode {
+	case "":
+		meta.Mode = ModeEphemeral
+	case ModeEphemeral, ModeDM, ModeChannelDigest:
+	default:
+		return nil, fmt.Errorf("Invalid mode: %s", meta.Mode)
+	}
+	if meta.Mode != ModeDM && meta.Channel == "" {
+		return nil, fmt.Errorf("channel required for %s mode", meta.Mode)
+	}
+	switch meta.PreviousMessage {
+	case "":
+	case PreviousMessageUpdate, PreviousMessageReply:
+		if meta.Mode == ModeEphemeral {
+			return nil, fmt.Errorf("previous_message not supported for %s mode", meta.Mode)
+		}
+	default:
+		return nil, fmt.Errorf("Invalid previous_message: %s", meta.PreviousMessage)
+	}
+	if meta.Interactive && meta.SigningSecretEnv == "" {
+		return nil, fmt.Errorf("signing_secret_env required for interactive")
+	}
+	return meta, nil
+}
+
+// setRuntime sets what the runner passed by the context
+func (m *MetaData) setRuntime(ctx context.Context) {
+	rt := reviewhub.RuntimeFrom(ctx)
+	m.approvable = rt.ApprovableLists
+	m.location = rt.Location
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+	return n.NotifyAll(ctx, config, []reviewhub.Notification{{User: user, ReviewLists: ls}})
+}
+
+// Batch reports true for channel_digest mode posting one digest for all users, and others notify per user
+func (n *SlackNotifier) Batch(config reviewhub.NotifierConfig) bool {
+	meta, err := parseMetaData(config)
+	return err == nil && meta.Mode == ModeChannelDigest
+}
+
+func (n *SlackNotifier) NotifyAll(ctx context.Context, config reviewhub.NotifierConfig, ns []reviewhub.Notification) error {
+	meta, err := parseMetaData(config)
 	if err != nil {
 		return err
 	}
+	meta.setRuntime(ctx)
 
 	cli := slack.New(os.Getenv(meta.ApiTokenEnv))
 
-	usermeta, err := reviewhub.ParseMetaData[UserMetaData](user.MetaData)
-	if err != nil {
-		// user metadata not satisfied
-		return nil
+	if meta.Mode == ModeChannelDigest {
+		return postDigest(ctx, cli, meta, ns)
+	}
+
+	errs := reviewhub.NotifyErrors{}
+	for _, v := range ns {
+		usermeta, err := reviewhub.ParseMetaData[UserMetaData](v.User.MetaData)
+		if err != nil {
+			// user metadata not satisfied
+			continue
+		}
+
+		if err := post(ctx, cli, meta, usermeta.SlackId, buildUserBlocks(meta, v)); err != nil {
+			log.Printf("Failed to send to %s: %v", v.User.Name, err)
+			errs[v.User.Name] = fmt.Errorf("Failed to send to %s: %w", v.User.Name, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// post sends blocks to the user by the mode
+func post(ctx context.Context, cli *slack.Client, meta *MetaData, slackId string, b []slack.Block) error {
+	if meta.Mode == ModeDM {
+		ch, _, _, err := cli.OpenConversationContext(ctx, &slack.OpenConversationParameters{Users: []string{slackId}})
+		if err != nil {
+			return fmt.Errorf("Failed to open conversation: %w", err)
+		}
+		return postOrUpdate(ctx, cli, meta, "dm:"+slackId, ch.ID, [][]slack.Block{b})
+	}
+
+	for _, m := range splitBlocks([][]slack.Block{b}) {
+		if _, err := cli.PostEphemeralContext(ctx, meta.Channel, slackId, slack.MsgOptionBlocks(m...)); err != nil {
+			return err
+		}
 	}
-	slackId := usermeta.SlackId
+	return nil
+}
 
+func buildUserBlocks(meta *MetaData, v reviewhub.Notification) []slack.Block {
 	var title string
 	if meta.Title != "" {
 		title = meta.Title
 	} else {
-		title = fmt.Sprintf("Notification For You (%s)", user.Name)
+		title = fmt.Sprintf("Notification For You (%s)", v.User.Name)
 	}
 
 	b := []slack.Block{
@@ -56,31 +177,217 @@ func (n *SlackNotifier) Notify(config reviewhub.NotifierConfig, user reviewhub.U
 		),
 	}
 
-	for _, c := range ls {
-		if len(c.Pages) > 0 {
+	for _, c := range v.ReviewLists {
+		if len(c.Pages) > 0 || c.Failed() {
 			// Page List
-			b = append(b, buildPageListBlock(c))
+			b = append(b, buildPageListBlocks(c, meta, v.User.Name)...)
 		}
 	}
 
 	if len(b) == 1 && meta.MessageIfVoid != "" {
-		// Add meta.MessageIfVoid text as markdown
-		b = append(b,
-			slack.NewSectionBlock(
-				slack.NewTextBlockObject(slack.MarkdownType, meta.MessageIfVoid, false, false),
-				nil, nil,
-			),
-		)
+		b = append(b, markdownBlock(meta.MessageIfVoid))
+	}
+	return b
+}
+
+// postDigest posts messages to the channel listing pending reviews of each user with a mention.
+// The digest is split into messages by users if it exceeds the block limit.
+func postDigest(ctx context.Context, cli *slack.Client, meta *MetaData, ns []reviewhub.Notification) error {
+	title := meta.Title
+	if title == "" {
+		title = "Pending Reviews"
+	}
+
+	head := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+		),
 	}
 
-	if _, err := cli.PostEphemeral(meta.Channel, slackId, slack.MsgOptionBlocks(b...)); err != nil {
-		log.Printf("Failed to send to %s: %v", user.Name, err)
+	// failures are shared by users, so show them once
+	failed := map[string]bool{}
+	for _, v := range ns {
+		for _, l := range v.ReviewLists {
+			if l.Failed() && !failed[l.Name] {
+				failed[l.Name] = true
+				head = append(head, buildPageListBlocks(reviewhub.ReviewList{Name: l.Name, Error: l.Error}, nil, "")...)
+			}
+		}
+	}
+
+	groups := [][]slack.Block{head}
+	for _, v := range ns {
+		var lists []slack.Block
+		for _, l := range v.ReviewLists {
+			if len(l.Pages) > 0 {
+				l.Error = ""
+				lists = append(lists, buildPageListBlocks(l, meta, v.User.Name)...)
+			}
+		}
+		if len(lists) == 0 {
+			continue
+		}
+
+		mention := v.User.Name
+		if usermeta, err := reviewhub.ParseMetaData[UserMetaData](v.User.MetaData); err == nil {
+			mention = fmt.Sprintf("<@%s>", usermeta.SlackId)
+		}
+		groups = append(groups, append([]slack.Block{slack.NewDividerBlock(), markdownBlock(mention)}, lists...))
+	}
+
+	if len(groups) == 1 {
+		// no pending review
+		if meta.MessageIfVoid != "" {
+			groups[0] = append(groups[0], markdownBlock(meta.MessageIfVoid))
+		} else if len(failed) == 0 {
+			return nil
+		}
 	}
 
+	if err := postOrUpdate(ctx, cli, meta, "digest", meta.Channel, groups); err != nil {
+		return fmt.Errorf("Failed to post digest to %s: %w", meta.Channel, err)
+	}
 	return nil
 }
 
-func buildPageListBlock(r reviewhub.ReviewList) slack.Block {
+// maxBlocks is the limit of blocks in a message by Slack
+const maxBlocks = 50
+
+// splitBlocks packs groups of blocks into messages within maxBlocks, splitting groups only if too large for a message
+func splitBlocks(groups [][]slack.Block) [][]slack.Block {
+	var msgs [][]slack.Block
+	var cur []slack.Block
+	for _, g := range groups {
+		if len(g) <= maxBlocks && len(cur)+len(g) > maxBlocks {
+			msgs = append(msgs, cur)
+			cur = nil
+		}
+		for len(cur)+len(g) > maxBlocks {
+			// too large group fills up messages
+			n := maxBlocks - len(cur)
+			msgs = append(msgs, append(cur, g[:n]...))
+			cur, g = nil, g[n:]
+		}
+		cur = append(cur, g...)
+	}
+	if len(cur) > 0 {
+		msgs = append(msgs, cur)
+	}
+	return msgs
+}
+
+// message is the last message posted for the key of the notifier state
+type message struct {
+	// Channel as configured, and ChannelID returned by Slack
+	Channel   string `json:"channel"`
+	ChannelID string `json:"channel_id"`
+	TS        string `json:"ts"`
+	// Day in the configured timezone the message is posted
+	Day string `json:"day"`
+}
+
+// postOrUpdate posts groups of blocks to the channel split by splitBlocks,
+// or updates or replies to the messages of the key posted earlier in the day.
+// Messages after the first are kept by the key with the index.
+func postOrUpdate(ctx context.Context, cli *slack.Client, meta *MetaData, key, channel string, groups [][]slack.Block) error {
+	msgs := splitBlocks(groups)
+
+	st, ok := reviewhub.NotifierStateFrom(ctx)
+	if !ok || meta.PreviousMessage == "" {
+		for _, b := range msgs {
+			if _, _, err := cli.PostMessageContext(ctx, channel, slack.MsgOptionBlocks(b...)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	today := time.Now().In(meta.location).Format(time.DateOnly)
+	for i, b := range msgs {
+		if err := postOrUpdateMessage(ctx, cli, meta, st, messageKey(key, i), channel, today, b); err != nil {
+			return err
+		}
+	}
+
+	// forget messages no longer needed, and delete ones updated otherwise
+	for i := len(msgs); ; i++ {
+		v, ok := st[messageKey(key, i)]
+		if !ok {
+			break
+		}
+		delete(st, messageKey(key, i))
+
+		var prev message
+		if json.Unmarshal([]byte(v), &prev) == nil && prev.Day == today && prev.Channel == channel && meta.PreviousMessage == PreviousMessageUpdate {
+			if _, _, err := cli.DeleteMessageContext(ctx, prev.ChannelID, prev.TS); err != nil {
+				log.Printf("Failed to delete message %s in %s: %v", prev.TS, channel, err)
+			}
+		}
+	}
+	return nil
+}
+
+func messageKey(key string, i int) string {
+	if i == 0 {
+		return key
+	}
+	return fmt.Sprintf("%s#%d", key, i)
+}
+
+func postOrUpdateMessage(ctx context.Context, cli *slack.Client, meta *MetaData, st reviewhub.NotifierState, key, channel, today string, b []slack.Block) error {
+	var prev message
+	if v, ok := st[key]; ok && json.Unmarshal([]byte(v), &prev) == nil && prev.Day == today && prev.Channel == channel {
+		switch meta.PreviousMessage {
+		case PreviousMessageUpdate:
+			_, _, _, err := cli.UpdateMessageContext(ctx, prev.ChannelID, prev.TS, slack.MsgOptionBlocks(b...))
+			if err == nil {
+				return nil
+			}
+			// the message may be deleted, so post new one
+			log.Printf("Failed to update message %s in %s: %v", prev.TS, channel, err)
+		case PreviousMessageReply:
+			_, _, err := cli.PostMessageContext(ctx, prev.ChannelID, slack.MsgOptionBlocks(b...), slack.MsgOptionTS(prev.TS))
+			return err
+		}
+	}
+
+	ch, ts, err := cli.PostMessageContext(ctx, channel, slack.MsgOptionBlocks(b...))
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(message{Channel: channel, ChannelID: ch, TS: ts, Day: today})
+	if err != nil {
+		return err
+	}
+	st[key] = string(v)
+	return nil
+}
+
+func markdownBlock(text string) slack.Block {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+		nil, nil,
+	)
+}
+
+var slaEmojis = map[reviewhub.SLAStatus]string{
+	reviewhub.SLAWarning:   "warning",
+	reviewhub.SLAEscalated: "rotating_light",
+}
+
+func pageDescription(page reviewhub.ReviewPage) string {
+	if page.Since().IsZero() {
+		return fmt.Sprintf("(by %s)", page.Owner.Name)
+	}
+	age := reviewhub.FormatAge(time.Since(page.Since()))
+	return fmt.Sprintf("(by %s, waiting %s)", page.Owner.Name, age)
+}
+
+// maxButtonPages is the number of pages with buttons in a list, as buttons take a block per page
+const maxButtonPages = 10
+
+// buildPageListBlocks builds a list of pages, with buttons of the first pages for the reviewer if interactive by meta (may be nil)
+func buildPageListBlocks(r reviewhub.ReviewList, meta *MetaData, reviewer string) []slack.Block {
 	// List Name
 	name := slack.NewRichTextSection(
 		slack.NewRichTextSectionTextElement(
@@ -91,6 +398,26 @@ func buildPageListBlock(r reviewhub.ReviewList) slack.Block {
 		),
 	)
 
+	els := []slack.RichTextElement{name}
+
+	// Warn that the list may be incomplete
+	if r.Failed() {
+		s := slack.NewRichTextSection(
+			// emoji
+			slack.NewRichTextSectionEmojiElement("warning", 2, nil),
+			// message
+			slack.NewRichTextSectionTextElement(
+				fmt.Sprintf("Failed to retrieve %s, so this list may be incomplete: %s", r.Name, r.Error),
+				&slack.RichTextSectionTextStyle{Italic: true},
+			),
+		)
+		els = append(els, s)
+
+		if len(r.Pages) == 0 {
+			return []slack.Block{slack.NewRichTextBlock("", els...)}
+		}
+	}
+
 	// Early return if no review page!
 	if len(r.Pages) == 0 {
 		s := slack.NewRichTextSection(
@@ -102,31 +429,92 @@ func buildPageListBlock(r reviewhub.ReviewList) slack.Block {
 				&slack.RichTextSectionTextStyle{Italic: true},
 			),
 		)
-		return slack.NewRichTextBlock("", name, s)
+		return []slack.Block{slack.NewRichTextBlock("", name, s)}
 	}
 
-	// Page List
-	var els []slack.RichTextElement
-	for _, page := range r.Pages {
-		// Building List Element...
-		s := slack.NewRichTextSection(
-			// Page URL
-			slack.NewRichTextSectionLinkElement(
-				page.Url,
-				page.Title,
-				&slack.RichTextSectionTextStyle{
-					Bold: true,
-				},
-			),
-			// Owner
-			slack.NewRichTextSectionTextElement(
-				fmt.Sprintf("(by %s)", page.Owner.Name),
-				&slack.RichTextSectionTextStyle{},
-			),
+	if meta != nil && meta.Interactive {
+		// buttons follow each page, and the rest are listed without buttons
+		approvable := slices.Contains(meta.approvable, r.Name)
+		b := []slack.Block{slack.NewRichTextBlock("", els...)}
+		n := min(len(r.Pages), maxButtonPages)
+		for _, page := range r.Pages[:n] {
+			list := slack.NewRichTextList(slack.RTEListBullet, 0, buildPageItem(page))
+			b = append(b, slack.NewRichTextBlock("", list), buildActionBlock(r.Name, page, reviewer, approvable))
+		}
+		if n < len(r.Pages) {
+			b = append(b, slack.NewRichTextBlock("", buildPageList(r.Pages[n:])))
+		}
+		return b
+	}
+
+	return []slack.Block{slack.NewRichTextBlock("", append(els, buildPageList(r.Pages))...)}
+}
+
+func buildPageList(pages []reviewhub.ReviewPage) slack.RichTextElement {
+	var items []slack.RichTextElement
+	for _, page := range pages {
+		items = append(items, buildPageItem(page))
+	}
+	return slack.NewRichTextList(slack.RTEListBullet, 0, items...) // Sum up to RichTextList block
+}
+
+func buildPageItem(page reviewhub.ReviewPage) slack.RichTextElement {
+	// Building List Element...
+	var pels []slack.RichTextSectionElement
+	if page.IsNew {
+		// NEW badge
+		pels = append(pels,
+			slack.NewRichTextSectionEmojiElement("new", 2, nil),
+			slack.NewRichTextSectionTextElement(" ", &slack.RichTextSectionTextStyle{}),
 		)
-		els = append(els, s)
 	}
-	list := slack.NewRichTextList(slack.RTEListBullet, 0, els...) // Sum up to RichTextList block
+	if emoji, ok := slaEmojis[page.SLAStatus]; ok {
+		// SLA highlight
+		pels = append(pels,
+			slack.NewRichTextSectionEmojiElement(emoji, 2, nil),
+			slack.NewRichTextSectionTextElement(" ", &slack.RichTextSectionTextStyle{}),
+		)
+	}
+	pels = append(pels,
+		// Page URL
+		slack.NewRichTextSectionLinkElement(
+			page.Url,
+			page.Title,
+			&slack.RichTextSectionTextStyle{
+				Bold: true,
+			},
+		),
+		// Owner and age
+		slack.NewRichTextSectionTextElement(
+			pageDescription(page),
+			&slack.RichTextSectionTextStyle{
+				Bold: page.SLAStatus == reviewhub.SLAEscalated,
+			},
+		),
+	)
+	return slack.NewRichTextSection(pels...)
+}
+
+// NotifyChannel posts escalated pages to the channel
+func (n *SlackNotifier) NotifyChannel(ctx context.Context, config reviewhub.NotifierConfig, channel string, ls []reviewhub.ReviewList) error {
+	meta, err := parseMetaData(config)
+	if err != nil {
+		return err
+	}
+	meta.setRuntime(ctx)
+
+	b := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, "Escalated Reviews", false, false),
+		),
+	}
+	for _, l := range ls {
+		b = append(b, buildPageListBlocks(l, nil, "")...)
+	}
 
-	return slack.NewRichTextBlock("", name, list)
+	cli := slack.New(os.Getenv(meta.ApiTokenEnv))
+	if _, _, err := cli.PostMessageContext(ctx, channel, slack.MsgOptionBlocks(b...)); err != nil {
+		return fmt.Errorf("Failed to post escalations to %s: %w", channel, err)
+	}
+	return nil
 }
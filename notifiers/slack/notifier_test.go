@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+	"github.com/slack-go/slack"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	group := func(n int) []slack.Block {
+		b := make([]slack.Block, n)
+		for i := range b {
+			b[i] = slack.NewDividerBlock()
+		}
+		return b
+	}
+
+	tests := []struct {
+		name   string
+		groups [][]slack.Block
+		want   []int
+	}{
+		{"empty", nil, nil},
+		{"one message", [][]slack.Block{group(1), group(20), group(29)}, []int{50}},
+		{"split by groups", [][]slack.Block{group(1), group(20), group(20), group(20)}, []int{41, 20}},
+		{"large group", [][]slack.Block{group(1), group(120)}, []int{50, 50, 21}},
+		{"after large group", [][]slack.Block{group(60), group(45)}, []int{50, 10, 45}},
+		{"exact", [][]slack.Block{group(50), group(50)}, []int{50, 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, m := range splitBlocks(tt.groups) {
+				got = append(got, len(m))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitBlocks() sizes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostOrUpdate(t *testing.T) {
+	// days in these zones always differ
+	east := time.FixedZone("UTC+14", 14*60*60)
+	west := time.FixedZone("UTC-12", -12*60*60)
+	prev := func(ts string) string {
+		b, _ := json.Marshal(message{Channel: "#reviews", ChannelID: "C1", TS: ts, Day: time.Now().In(east).Format(time.DateOnly)})
+		return string(b)
+	}
+
+	tests := []struct {
+		name      string
+		location  *time.Location
+		groups    int
+		wantCalls []string
+		wantKeys  []string
+	}{
+		{"update today", east, 1, []string{"chat.update 1.0", "chat.delete 2.0"}, []string{"digest"}},
+		{"post on another day", west, 1, []string{"chat.postMessage"}, []string{"digest"}},
+		{"post more messages", east, 2, []string{"chat.update 1.0", "chat.update 2.0"}, []string{"digest", "digest#1"}},
+		{"post new messages", east, 3, []string{"chat.update 1.0", "chat.update 2.0", "chat.postMessage"}, []string{"digest", "digest#1", "digest#2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				method := strings.TrimPrefix(r.URL.Path, "/")
+				if ts := r.Form.Get("ts"); ts != "" {
+					method += " " + ts
+				}
+				calls = append(calls, method)
+				fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"9.0"}`)
+			}))
+			defer srv.Close()
+
+			cli := slack.New("token", slack.OptionAPIURL(srv.URL+"/"))
+			meta := &MetaData{Channel: "#reviews", PreviousMessage: PreviousMessageUpdate, location: tt.location}
+			st := reviewhub.NotifierState{"digest": prev("1.0"), "digest#1": prev("2.0")}
+			ctx := reviewhub.WithNotifierState(context.Background(), st)
+
+			var groups [][]slack.Block
+			for i := 0; i < tt.groups; i++ {
+				b := make([]slack.Block, maxBlocks)
+				for j := range b {
+					b[j] = slack.NewDividerBlock()
+				}
+				groups = append(groups, b)
+			}
+
+			if err := postOrUpdate(ctx, cli, meta, "digest", "#reviews", groups); err != nil {
+				t.Fatalf("postOrUpdate() error = %v", err)
+			}
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			var keys []string
+			for k := range st {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("state keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package stdout
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -15,6 +16,10 @@ const (
 type StdoutNotifier struct {
 }
 
+func init() {
+	reviewhub.RegisterNotifier("stdout", func() reviewhub.Notifier { return new(StdoutNotifier) })
+}
+
 type MetaData struct {
 	Format string `yaml:"format"`
 }
@@ -36,7 +41,7 @@ func (m *MetaData) Validate() error {
 	return fmt.Errorf("Invalid format type: %s", m.Format)
 }
 
-func (n *StdoutNotifier) Notify(config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+func (n *StdoutNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
 	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
 	if err != nil {
 		return err
@@ -49,13 +54,7 @@ func (n *StdoutNotifier) Notify(config reviewhub.NotifierConfig, user reviewhub.
 	switch meta.Format {
 	case FormatPlainText:
 		fmt.Printf("User: %s\n", user.Name)
-		for _, l := range ls {
-			fmt.Printf("ReviewName: %s\n", l.Name)
-			for _, p := range l.Pages {
-				fmt.Printf("- %s\n", p.Title)
-			}
-		}
-		fmt.Println("")
+		printLists(ls)
 
 	case FormatJson:
 		notif := notification{
@@ -74,7 +73,69 @@ func (n *StdoutNotifier) Notify(config reviewhub.NotifierConfig, user reviewhub.
 	return nil
 }
 
+// NotifyChannel prints escalated pages of the channel
+func (n *StdoutNotifier) NotifyChannel(ctx context.Context, config reviewhub.NotifierConfig, channel string, ls []reviewhub.ReviewList) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+
+	if err := meta.Validate(); err != nil {
+		return err
+	}
+
+	switch meta.Format {
+	case FormatPlainText:
+		fmt.Printf("Channel: %s\n", channel)
+		printLists(ls)
+
+	case FormatJson:
+		b, err := json.Marshal(channelNotification{
+			Channel:     channel,
+			ReviewLists: ls,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	}
+	return nil
+}
+
+func printLists(ls []reviewhub.ReviewList) {
+	for _, l := range ls {
+		if l.Failed() {
+			fmt.Printf("ReviewName: %s (failed to retrieve, may be incomplete: %s)\n", l.Name, l.Error)
+		} else {
+			fmt.Printf("ReviewName: %s\n", l.Name)
+		}
+		for _, p := range l.Pages {
+			fmt.Printf("- %s%s\n", p.Title, marks(p))
+		}
+	}
+	fmt.Println("")
+}
+
+func marks(p reviewhub.ReviewPage) string {
+	var m string
+	if p.IsNew {
+		m += " (NEW)"
+	}
+	switch p.SLAStatus {
+	case reviewhub.SLAWarning:
+		m += " (SLA warning)"
+	case reviewhub.SLAEscalated:
+		m += " (SLA escalated)"
+	}
+	return m
+}
+
 type notification struct {
 	User        reviewhub.User
 	ReviewLists []reviewhub.ReviewList
 }
+
+type channelNotification struct {
+	Channel     string
+	ReviewLists []reviewhub.ReviewList
+}
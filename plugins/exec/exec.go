@@ -0,0 +1,48 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"strings"
+)
+
+type MetaData struct {
+	Command string   `yaml:"command" validate:"required"`
+	Args    []string `yaml:"args"`
+	Dir     string   `yaml:"dir"`
+}
+
+// run executes the plugin command, writes req as json to stdin and decodes stdout into res
+func run(ctx context.Context, meta *MetaData, req any, res any) error {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := osexec.CommandContext(ctx, meta.Command, meta.Args...)
+	cmd.Dir = meta.Dir
+	cmd.Env = os.Environ()
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("Plugin %s stopped: %w", meta.Command, ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("Plugin %s failed: %w: %s", meta.Command, err, msg)
+		}
+		return fmt.Errorf("Plugin %s failed: %w", meta.Command, err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), res); err != nil {
+		return fmt.Errorf("Plugin %s returned invalid json: %w", meta.Command, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// ExecNotifier notifies through an external command.
+// The command receives notifyRequest as json on stdin and must print notifyResponse as json on stdout.
+type ExecNotifier struct {
+}
+
+func init() {
+	reviewhub.RegisterNotifier("exec", func() reviewhub.Notifier { return new(ExecNotifier) })
+}
+
+func (n *ExecNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+
+	req := notifyRequest{
+		Config: pluginConfig{
+			Name:     config.Name,
+			Type:     config.Type,
+			MetaData: normalize(config.MetaData),
+		},
+		Users:       fromUsers(reviewhub.RuntimeFrom(ctx).Users),
+		User:        fromUser(user),
+		ReviewLists: fromReviewLists(ls),
+	}
+
+	var res notifyResponse
+	if err := run(ctx, meta, req, &res); err != nil {
+		return err
+	}
+
+	switch res.Status {
+	case StatusOk:
+		return nil
+	case StatusError:
+		return fmt.Errorf("Plugin %s reported error: %s", meta.Command, res.Message)
+	}
+	return fmt.Errorf("Plugin %s returned invalid status: %s", meta.Command, res.Status)
+}
@@ -0,0 +1,149 @@
+package exec
+
+import (
+	"fmt"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// JSON messages exchanged with plugin processes via stdin/stdout
+
+type pluginConfig struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	MetaData any    `json:"metadata"`
+}
+
+type user struct {
+	Name     string `json:"name"`
+	MetaData any    `json:"metadata,omitempty"`
+	Unknown  bool   `json:"unknown,omitempty"`
+}
+
+type page struct {
+	Title             string `json:"title"`
+	Url               string `json:"url"`
+	Owner             user   `json:"owner"`
+	ApprovedReviewers []user `json:"approved_reviewers"`
+	Reviewers         []user `json:"reviewers"`
+}
+
+type reviewList struct {
+	Name  string `json:"name"`
+	Pages []page `json:"pages"`
+	Error string `json:"error,omitempty"`
+}
+
+type retrieveRequest struct {
+	Config pluginConfig        `json:"config"`
+	Users  []user              `json:"users"`
+	Groups map[string][]string `json:"groups,omitempty"`
+}
+
+type notifyRequest struct {
+	Config      pluginConfig `json:"config"`
+	Users       []user       `json:"users"`
+	User        user         `json:"user"`
+	ReviewLists []reviewList `json:"review_lists"`
+}
+
+const (
+	StatusOk    = "ok"
+	StatusError = "error"
+)
+
+type notifyResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func fromUser(u reviewhub.User) user {
+	return user{
+		Name:     u.Name,
+		MetaData: normalize(u.MetaData),
+		Unknown:  u.Unknown,
+	}
+}
+
+func fromUsers(us []reviewhub.User) []user {
+	res := []user{}
+	for _, u := range us {
+		res = append(res, fromUser(u))
+	}
+	return res
+}
+
+func fromReviewLists(ls []reviewhub.ReviewList) []reviewList {
+	res := []reviewList{}
+	for _, l := range ls {
+		pages := []page{}
+		for _, p := range l.Pages {
+			pages = append(pages, page{
+				Title:             p.Title,
+				Url:               p.Url,
+				Owner:             fromUser(p.Owner),
+				ApprovedReviewers: fromUsers(p.ApprovedReviewers),
+				Reviewers:         fromUsers(p.Reviewers),
+			})
+		}
+		res = append(res, reviewList{Name: l.Name, Pages: pages, Error: l.Error})
+	}
+	return res
+}
+
+// toUser resolves a user returned from plugin by name in known users
+func (u user) toUser(knownUsers []reviewhub.User) reviewhub.User {
+	for _, k := range knownUsers {
+		if k.Name == u.Name {
+			return k
+		}
+	}
+	return *reviewhub.NewUnknownUser(u.Name)
+}
+
+func toUsers(us []user, knownUsers []reviewhub.User) []reviewhub.User {
+	var res []reviewhub.User
+	for _, u := range us {
+		res = append(res, u.toUser(knownUsers))
+	}
+	return res
+}
+
+func (l reviewList) toReviewList(knownUsers []reviewhub.User) reviewhub.ReviewList {
+	var pages []reviewhub.ReviewPage
+	for _, p := range l.Pages {
+		pages = append(pages, reviewhub.NewReviewPage(
+			p.Title,
+			p.Url,
+			p.Owner.toUser(knownUsers),
+			toUsers(p.ApprovedReviewers, knownUsers),
+			toUsers(p.Reviewers, knownUsers),
+		))
+	}
+	return reviewhub.ReviewList{Name: l.Name, Pages: pages}
+}
+
+// normalize converts maps decoded from yaml (map[interface{}]interface{}) into json encodable ones
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := map[string]any{}
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case map[string]any:
+		m := map[string]any{}
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = normalize(e)
+		}
+		return s
+	}
+	return v
+}
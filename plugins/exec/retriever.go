@@ -0,0 +1,43 @@
+package exec
+
+import (
+	"context"
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// ExecRetriever retrieves a review list from an external command.
+// The command receives retrieveRequest as json on stdin and must print reviewList as json on stdout.
+type ExecRetriever struct {
+}
+
+func init() {
+	reviewhub.RegisterRetriever("exec", func() reviewhub.Retriever { return new(ExecRetriever) })
+}
+
+func (p *ExecRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return nil, err
+	}
+
+	req := retrieveRequest{
+		Config: pluginConfig{
+			Name:     config.Name,
+			Type:     config.Type,
+			MetaData: normalize(config.MetaData),
+		},
+		Users:  fromUsers(knownUsers),
+		Groups: config.Groups,
+	}
+
+	var res reviewList
+	if err := run(ctx, meta, req, &res); err != nil {
+		return nil, err
+	}
+
+	l := res.toReviewList(knownUsers)
+	if l.Name == "" {
+		l.Name = config.Name
+	}
+	return &l, nil
+}
@@ -0,0 +1,79 @@
+package ghdiscussions
+
+import (
+	"slices"
+	"strings"
+)
+
+// ApprovalSignals declares what counts as an approval of a discussion by a user
+type ApprovalSignals struct {
+	// Comment approves by any comment of the user
+	Comment bool `yaml:"comment"`
+	// Reactions approve by reacting the discussion, like "+1" or "THUMBS_UP"
+	Reactions []string `yaml:"reactions"`
+	// Keywords approve by a comment containing any of them case-insensitively, like "LGTM"
+	Keywords []string `yaml:"keywords"`
+}
+
+// reactionAliases maps emoji names to GitHub ReactionContent
+var reactionAliases = map[string]string{
+	"+1":       "THUMBS_UP",
+	"thumbsup": "THUMBS_UP",
+	"-1":       "THUMBS_DOWN",
+	"laugh":    "LAUGH",
+	"smile":    "LAUGH",
+	"hooray":   "HOORAY",
+	"tada":     "HOORAY",
+	"confused": "CONFUSED",
+	"heart":    "HEART",
+	"rocket":   "ROCKET",
+	"eyes":     "EYES",
+}
+
+func normalizeReaction(r string) string {
+	r = strings.Trim(strings.TrimSpace(r), ":")
+	if v, ok := reactionAliases[strings.ToLower(r)]; ok {
+		return v
+	}
+	return strings.ToUpper(r)
+}
+
+// approvers returns logins who approved the page by the signals
+func (s *ApprovalSignals) approvers(p page) []string {
+	if s == nil {
+		return nil
+	}
+
+	var logins []string
+	add := func(login string) {
+		if login != "" && !slices.Contains(logins, login) {
+			logins = append(logins, login)
+		}
+	}
+
+	for _, c := range p.comments {
+		if s.Comment {
+			add(c.authorLogin)
+			continue
+		}
+		body := strings.ToLower(c.body)
+		for _, k := range s.Keywords {
+			if strings.Contains(body, strings.ToLower(k)) {
+				add(c.authorLogin)
+				break
+			}
+		}
+	}
+
+	var contents []string
+	for _, r := range s.Reactions {
+		contents = append(contents, normalizeReaction(r))
+	}
+	for _, r := range p.reactions {
+		if slices.Contains(contents, r.content) {
+			add(r.userLogin)
+		}
+	}
+
+	return logins
+}
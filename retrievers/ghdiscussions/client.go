@@ -1,118 +1,358 @@
 package ghdiscussions
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
+	"time"
 
-	"github.com/buger/jsonparser"
+	"github.com/ry023/reviewhub/internal/github"
 )
 
 type page struct {
+	id          string
 	isAnswered  bool
 	closed      bool
 	title       string
 	url         string
+	body        string
 	authorLogin string
+	createdAt   time.Time
+	updatedAt   time.Time
+	category    string
+	labels      []string
+	comments    []comment
+	reactions   []reaction
 }
 
-func request(repositoryOwner, repository, token, apiEndpoint string) ([]page, error) {
-	// GraphQL Query
-	query := fmt.Sprintf(`
-query {
-  repository(owner: "%s", name: "%s") {
-    discussions(last: 100) {
+type comment struct {
+	authorLogin string
+	body        string
+}
+
+type reaction struct {
+	content   string
+	userLogin string
+}
+
+const discussionsQuery = `
+query($owner: String!, $name: String!, $after: String, $categoryId: ID, $withApproval: Boolean!) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, after: $after, categoryId: $categoryId, states: [OPEN], orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
       nodes {
+        id
         isAnswered
         closed
         title
         url
+        body
+        createdAt
+        updatedAt
         author {
           login
         }
+        category {
+          name
+        }
+        labels(first: 100) {
+          nodes {
+            name
+          }
+        }
+        comments(first: 100) @include(if: $withApproval) {
+          ...commentsFields
+        }
+        reactions(first: 100) @include(if: $withApproval) {
+          ...reactionsFields
+        }
       }
     }
   }
 }
-`,
-		repositoryOwner, repository,
-	)
+` + connectionFragments
 
-	// build json request body
-	body := struct {
-		Query string `json:"query"`
-	}{Query: query}
-	bodybytes, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
+// connectionFragments are fields of comments and reactions shared by queries of discussions and their rest pages
+const connectionFragments = `
+fragment commentsFields on DiscussionCommentConnection {
+  pageInfo {
+    hasNextPage
+    endCursor
+  }
+  nodes {
+    body
+    author {
+      login
+    }
+  }
+}
 
-	// build http request
-	req, err := http.NewRequest(http.MethodPost, apiEndpoint, bytes.NewReader(bodybytes))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", "bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+fragment reactionsFields on ReactionConnection {
+  pageInfo {
+    hasNextPage
+    endCursor
+  }
+  nodes {
+    content
+    user {
+      login
+    }
+  }
+}
+`
 
-	// do request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+type commentsConnection struct {
+	PageInfo github.PageInfo `json:"pageInfo"`
+	Nodes    []struct {
+		Body   string       `json:"body"`
+		Author github.Actor `json:"author"`
+	} `json:"nodes"`
+}
+
+type reactionsConnection struct {
+	PageInfo github.PageInfo `json:"pageInfo"`
+	Nodes    []struct {
+		Content string       `json:"content"`
+		User    github.Actor `json:"user"`
+	} `json:"nodes"`
+}
+
+type discussionsResponse struct {
+	Repository struct {
+		Discussions struct {
+			PageInfo github.PageInfo `json:"pageInfo"`
+			Nodes    []struct {
+				Id         string       `json:"id"`
+				IsAnswered bool         `json:"isAnswered"`
+				Closed     bool         `json:"closed"`
+				Title      string       `json:"title"`
+				Url        string       `json:"url"`
+				Body       string       `json:"body"`
+				CreatedAt  time.Time    `json:"createdAt"`
+				UpdatedAt  time.Time    `json:"updatedAt"`
+				Author     github.Actor `json:"author"`
+				// category may be hidden by permission
+				Category *struct {
+					Name string `json:"name"`
+				} `json:"category"`
+				Labels struct {
+					Nodes []struct {
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"labels"`
+				Comments  commentsConnection  `json:"comments"`
+				Reactions reactionsConnection `json:"reactions"`
+			} `json:"nodes"`
+		} `json:"discussions"`
+	} `json:"repository"`
+}
+
+// listDiscussions fetches open discussions in the category (or all if empty) created after since (or all if zero) over all pages.
+// Comments and reactions are fetched only withApproval, as they are heavy and used only for approval signals.
+func listDiscussions(ctx context.Context, cli *github.Client, repositoryOwner, repository, categoryId string, since time.Time, withApproval bool) ([]page, error) {
+	var pages []page
+
+	vars := map[string]any{
+		"owner":        repositoryOwner,
+		"name":         repository,
+		"withApproval": withApproval,
 	}
-	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if categoryId != "" {
+		vars["categoryId"] = categoryId
 	}
+	more := true
+	for more {
+		var res discussionsResponse
+		if err := cli.Query(ctx, discussionsQuery, vars, &res); err != nil {
+			return nil, err
+		}
 
-	pages := []page{}
-	_, err = jsonparser.ArrayEach(
-		respBody,
-		// handler
-		func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
-			if err != nil {
-				log.Printf("Failed to parse nodes: %v", err)
-				return
+		for _, n := range res.Repository.Discussions.Nodes {
+			if !since.IsZero() && n.CreatedAt.Before(since) {
+				// discussions are ordered by newest first, so the rest are older
+				return pages, nil
+			}
+			if n.Author.Login == "" {
+				// skip discussions by deleted accounts
+				continue
 			}
 
-			isAnswered, err := jsonparser.GetBoolean(value, "isAnswered")
-			if err != nil {
-				return
+			p := page{
+				id:          n.Id,
+				isAnswered:  n.IsAnswered,
+				closed:      n.Closed,
+				title:       n.Title,
+				url:         n.Url,
+				body:        n.Body,
+				authorLogin: n.Author.Login,
+				createdAt:   n.CreatedAt,
+				updatedAt:   n.UpdatedAt,
 			}
-			closed, err := jsonparser.GetBoolean(value, "closed")
-			if err != nil {
-				return
+			if n.Category != nil {
+				p.category = n.Category.Name
 			}
-			title, err := jsonparser.GetString(value, "title")
-			if err != nil {
-				return
+			for _, l := range n.Labels.Nodes {
+				p.labels = append(p.labels, l.Name)
 			}
-			url, err := jsonparser.GetString(value, "url")
+			// busy discussions have approvals beyond the first page
+			comments, err := restComments(ctx, cli, n.Id, n.Comments)
 			if err != nil {
-				return
+				return nil, err
+			}
+			for _, c := range comments.Nodes {
+				p.comments = append(p.comments, comment{authorLogin: c.Author.Login, body: c.Body})
 			}
-			authorLogin, err := jsonparser.GetString(value, "author", "login")
+			reactions, err := restReactions(ctx, cli, n.Id, n.Reactions)
 			if err != nil {
-				return
+				return nil, err
 			}
-			pages = append(pages, page{
-				isAnswered:  isAnswered,
-				closed:      closed,
-				title:       title,
-				url:         url,
-				authorLogin: authorLogin,
-			})
-		},
-		// array path
-		"data", "repository", "discussions", "nodes",
-	)
-	if err != nil {
-		return nil, err
+			for _, r := range reactions.Nodes {
+				p.reactions = append(p.reactions, reaction{content: r.Content, userLogin: r.User.Login})
+			}
+			pages = append(pages, p)
+		}
+
+		info := res.Repository.Discussions.PageInfo
+		more = info.HasNextPage
+		vars["after"] = info.EndCursor
 	}
+
 	return pages, nil
 }
+
+const restCommentsQuery = `
+query($id: ID!, $after: String) {
+  node(id: $id) {
+    ... on Discussion {
+      comments(first: 100, after: $after) {
+        ...commentsFields
+      }
+    }
+  }
+}
+` + connectionFragments
+
+// restComments appends comments after the first page to it
+func restComments(ctx context.Context, cli *github.Client, discussionId string, first commentsConnection) (commentsConnection, error) {
+	all := first
+	info := first.PageInfo
+	for info.HasNextPage {
+		var res struct {
+			Node struct {
+				Comments commentsConnection `json:"comments"`
+			} `json:"node"`
+		}
+		vars := map[string]any{"id": discussionId, "after": info.EndCursor}
+		if err := cli.Query(ctx, restCommentsQuery, vars, &res); err != nil {
+			return all, fmt.Errorf("Failed to fetch comments of %s: %w", discussionId, err)
+		}
+		all.Nodes = append(all.Nodes, res.Node.Comments.Nodes...)
+		info = res.Node.Comments.PageInfo
+	}
+	return all, nil
+}
+
+const restReactionsQuery = `
+query($id: ID!, $after: String) {
+  node(id: $id) {
+    ... on Discussion {
+      reactions(first: 100, after: $after) {
+        ...reactionsFields
+      }
+    }
+  }
+}
+` + connectionFragments
+
+// restReactions appends reactions after the first page to it
+func restReactions(ctx context.Context, cli *github.Client, discussionId string, first reactionsConnection) (reactionsConnection, error) {
+	all := first
+	info := first.PageInfo
+	for info.HasNextPage {
+		var res struct {
+			Node struct {
+				Reactions reactionsConnection `json:"reactions"`
+			} `json:"node"`
+		}
+		vars := map[string]any{"id": discussionId, "after": info.EndCursor}
+		if err := cli.Query(ctx, restReactionsQuery, vars, &res); err != nil {
+			return all, fmt.Errorf("Failed to fetch reactions of %s: %w", discussionId, err)
+		}
+		all.Nodes = append(all.Nodes, res.Node.Reactions.Nodes...)
+		info = res.Node.Reactions.PageInfo
+	}
+	return all, nil
+}
+
+const categoriesQuery = `
+query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    discussionCategories(first: 100) {
+      nodes {
+        id
+        name
+      }
+    }
+  }
+}
+`
+
+type categoriesResponse struct {
+	Repository struct {
+		DiscussionCategories struct {
+			Nodes []struct {
+				Id   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"nodes"`
+		} `json:"discussionCategories"`
+	} `json:"repository"`
+}
+
+// categoryIds resolves names of discussion categories into node ids, which repositories have up to 25 of
+func categoryIds(ctx context.Context, cli *github.Client, repositoryOwner, repository string, names []string) ([]string, error) {
+	vars := map[string]any{
+		"owner": repositoryOwner,
+		"name":  repository,
+	}
+	var res categoriesResponse
+	if err := cli.Query(ctx, categoriesQuery, vars, &res); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, name := range names {
+		found := false
+		for _, c := range res.Repository.DiscussionCategories.Nodes {
+			if c.Name == name {
+				ids = append(ids, c.Id)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown discussion category: %s", name)
+		}
+	}
+	return ids, nil
+}
+
+const addCommentMutation = `
+mutation($discussionId: ID!, $body: String!) {
+  addDiscussionComment(input: {discussionId: $discussionId, body: $body}) {
+    comment {
+      id
+    }
+  }
+}
+`
+
+// addComment posts a comment with body to the discussion of the node id
+func addComment(ctx context.Context, cli *github.Client, discussionId, body string) error {
+	vars := map[string]any{
+		"discussionId": discussionId,
+		"body":         body,
+	}
+	var res struct{}
+	return cli.Mutate(ctx, addCommentMutation, vars, &res)
+}
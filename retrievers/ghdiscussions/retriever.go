@@ -1,43 +1,67 @@
 package ghdiscussions
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"time"
 
+	"github.com/ry023/reviewhub/internal/github"
 	"github.com/ry023/reviewhub/reviewhub"
 )
 
 type GitHubDiscussionsRetriever struct {
 }
 
+func init() {
+	reviewhub.RegisterRetriever("github-discussions", func() reviewhub.Retriever { return new(GitHubDiscussionsRetriever) })
+}
+
 type MetaData struct {
 	RepositoryOwner string `yaml:"repository_owner"`
 	RepositoryName  string `yaml:"repository_name"`
 	ApiTokenEnv     string `yaml:"api_token_env" validate:"required"`
 	ApiEndpoint     string `yaml:"api_endpoint"`
+
+	// Filters: discussions in any of categories, having any of labels, and created within max_age
+	Categories []string           `yaml:"categories"`
+	Labels     []string           `yaml:"labels"`
+	MaxAge     reviewhub.Duration `yaml:"max_age"`
+
+	Approval *ApprovalSignals `yaml:"approval"`
+
+	// Reviewer rules by user or group names: union of them, or all known users if none.
+	// The author is always excluded.
+	StaticReviewers     []string            `yaml:"static_reviewers"`
+	ReviewersByCategory map[string][]string `yaml:"reviewers_by_category"`
+	ReviewersByLabel    map[string][]string `yaml:"reviewers_by_label"`
+	MentionedReviewers  bool                `yaml:"mentioned_reviewers"`
 }
 
 type UserMetaData struct {
 	GitHubId string `yaml:"github_id"`
 }
 
-const defaultApiEndpoint = "https://api.github.com/graphql"
-
-func (p *GitHubDiscussionsRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
+func (p *GitHubDiscussionsRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
 	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
 	if err != nil {
 		return nil, err
 	}
 
-	token := os.Getenv(meta.ApiTokenEnv)
-	apiEndpoint := defaultApiEndpoint
-	if meta.ApiEndpoint != "" {
-		apiEndpoint = meta.ApiEndpoint
+	cli := github.NewClient(meta.ApiEndpoint, os.Getenv(meta.ApiTokenEnv))
+
+	var since time.Time
+	if meta.MaxAge > 0 {
+		since = time.Now().Add(-time.Duration(meta.MaxAge))
 	}
 
 	l := []reviewhub.ReviewPage{}
-	pages, err := request(meta.RepositoryOwner, meta.RepositoryName, token, apiEndpoint)
+	pages, err := meta.listDiscussions(ctx, cli, since)
 	if err != nil {
-		return nil, err
+		repo := fmt.Sprintf("repository %s/%s", meta.RepositoryOwner, meta.RepositoryName)
+		return nil, reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, repo)
 	}
 
 	for _, u := range knownUsers {
@@ -47,12 +71,25 @@ func (p *GitHubDiscussionsRetriever) Retrieve(config reviewhub.RetrieverConfig,
 		}
 
 		for _, page := range pages {
+			if !meta.match(page) {
+				continue
+			}
+
 			if page.authorLogin == umeta.GitHubId || page.authorLogin == u.Name {
 				if !page.closed && !page.isAnswered {
-					l = append(l,
-						// all member as reviewers and 0 approved members
-						reviewhub.NewReviewPage(page.title, page.url, u, []reviewhub.User{}, knownUsers),
-					)
+					// approved by the signals
+					approved := []reviewhub.User{}
+					for _, login := range meta.Approval.approvers(page) {
+						if a := findUser(login, knownUsers); a != nil && a.Name != u.Name {
+							approved = append(approved, *a)
+						}
+					}
+
+					p := reviewhub.NewReviewPage(page.title, page.url, u, approved, meta.reviewers(page, u, config.Groups, knownUsers))
+					p.ID = page.id
+					p.CreatedAt = page.createdAt
+					p.UpdatedAt = page.updatedAt
+					l = append(l, p)
 				}
 			}
 		}
@@ -63,3 +100,124 @@ func (p *GitHubDiscussionsRetriever) Retrieve(config reviewhub.RetrieverConfig,
 		Pages: l,
 	}, nil
 }
+
+// listDiscussions fetches discussions of the repository, per category if filtered by categories
+func (m *MetaData) listDiscussions(ctx context.Context, cli *github.Client, since time.Time) ([]page, error) {
+	withApproval := m.Approval != nil
+	if len(m.Categories) == 0 {
+		return listDiscussions(ctx, cli, m.RepositoryOwner, m.RepositoryName, "", since, withApproval)
+	}
+
+	ids, err := categoryIds(ctx, cli, m.RepositoryOwner, m.RepositoryName, m.Categories)
+	if err != nil {
+		return nil, err
+	}
+	var pages []page
+	for _, id := range ids {
+		ps, err := listDiscussions(ctx, cli, m.RepositoryOwner, m.RepositoryName, id, since, withApproval)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, ps...)
+	}
+	return pages, nil
+}
+
+// match reports whether the page satisfies category and label filters
+func (m *MetaData) match(p page) bool {
+	if len(m.Categories) > 0 && !slices.Contains(m.Categories, p.category) {
+		return false
+	}
+
+	if len(m.Labels) > 0 {
+		for _, l := range p.labels {
+			if slices.Contains(m.Labels, l) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w/])@([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)`)
+
+func (m *MetaData) hasReviewerRules() bool {
+	return len(m.StaticReviewers) > 0 || len(m.ReviewersByCategory) > 0 || len(m.ReviewersByLabel) > 0 || m.MentionedReviewers
+}
+
+// reviewers returns known users assigned to the page by reviewer rules, excluding the author
+func (m *MetaData) reviewers(p page, author reviewhub.User, groups reviewhub.Groups, knownUsers []reviewhub.User) []reviewhub.User {
+	if !m.hasReviewerRules() {
+		var all []reviewhub.User
+		for _, u := range knownUsers {
+			if u.Name != author.Name {
+				all = append(all, u)
+			}
+		}
+		return all
+	}
+
+	names := slices.Clone(m.StaticReviewers)
+	names = append(names, m.ReviewersByCategory[p.category]...)
+	for _, l := range p.labels {
+		names = append(names, m.ReviewersByLabel[l]...)
+	}
+
+	var reviewers []reviewhub.User
+	add := func(u reviewhub.User) {
+		if u.Name != author.Name && !reviewhub.Contains(reviewers, u) {
+			reviewers = append(reviewers, u)
+		}
+	}
+
+	names = groups.Expand(names)
+	for _, u := range knownUsers {
+		if slices.Contains(names, u.Name) {
+			add(u)
+		}
+	}
+
+	if m.MentionedReviewers {
+		for _, match := range mentionPattern.FindAllStringSubmatch(p.body, -1) {
+			if u := findUser(match[1], knownUsers); u != nil {
+				add(*u)
+			}
+		}
+	}
+
+	return reviewers
+}
+
+// findUser searches known users by github_id metadata or name
+func findUser(login string, knownUsers []reviewhub.User) *reviewhub.User {
+	return reviewhub.FindUser(knownUsers, login, func(m UserMetaData) string { return m.GitHubId })
+}
+
+// Comment posts the message mentioning users by github_id or name to the discussion
+func (p *GitHubDiscussionsRetriever) Comment(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, message string, mentions []reviewhub.User) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if page.ID == "" {
+		return fmt.Errorf("Discussion id of %s unknown", page.Url)
+	}
+
+	body := message
+	for _, u := range mentions {
+		login := u.Name
+		if umeta, err := reviewhub.ParseMetaData[UserMetaData](u.MetaData); err == nil && umeta.GitHubId != "" {
+			login = umeta.GitHubId
+		}
+		body += " @" + login
+	}
+
+	cli := github.NewClient(meta.ApiEndpoint, os.Getenv(meta.ApiTokenEnv))
+	if err := addComment(ctx, cli, page.ID, body); err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "discussion "+page.Url)
+		return fmt.Errorf("Failed to comment: %w", err)
+	}
+	return nil
+}
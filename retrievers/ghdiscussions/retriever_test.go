@@ -0,0 +1,161 @@
+package ghdiscussions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// fakeGitHub serves categories and discussions by the category id, and records discussions queries
+type fakeGitHub struct {
+	discussions map[string]string // category id -> nodes json
+	queried     []string          // category ids
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case strings.Contains(body.Query, "discussionCategories"):
+		fmt.Fprint(w, `{"data":{"repository":{"discussionCategories":{"nodes":[
+			{"id":"C1","name":"Reviews"},{"id":"C2","name":"Ideas"}]}}}}`)
+	case strings.Contains(body.Query, "comments(first: 100, after: $after)"):
+		fmt.Fprint(w, `{"data":{"node":{"comments":{"pageInfo":{"hasNextPage":false},"nodes":[
+			{"body":"LGTM!","author":{"login":"bob"}}]}}}}`)
+	case strings.Contains(body.Query, "reactions(first: 100, after: $after)"):
+		if body.Variables["after"] == "r1" {
+			fmt.Fprint(w, `{"data":{"node":{"reactions":{"pageInfo":{"hasNextPage":true,"endCursor":"r2"},"nodes":[
+				{"content":"HEART","user":{"login":"carol"}}]}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"node":{"reactions":{"pageInfo":{"hasNextPage":false},"nodes":[
+			{"content":"THUMBS_UP","user":{"login":"carol"}}]}}}}`)
+	case strings.Contains(body.Query, "states: [OPEN]"):
+		id, _ := body.Variables["categoryId"].(string)
+		f.queried = append(f.queried, id)
+		fmt.Fprintf(w, `{"data":{"repository":{"discussions":{"pageInfo":{"hasNextPage":false},"nodes":[%s]}}}}`, f.discussions[id])
+	default:
+		fmt.Fprint(w, `{"errors":[{"message":"discussions must be open only"}]}`)
+	}
+}
+
+func discussion(id, category string) string {
+	return fmt.Sprintf(`{"id":%q,"title":%q,"url":"https://github.com/o/r/discussions/%s",
+		"createdAt":"2026-10-01T00:00:00Z","updatedAt":"2026-10-01T00:00:00Z",
+		"author":{"login":"alice"},"category":{"name":%q},"labels":{"nodes":[]}}`, id, id, id, category)
+}
+
+func TestRetrieveCategories(t *testing.T) {
+	tests := []struct {
+		name        string
+		categories  []any
+		wantQueried []string
+		wantPages   []string
+		wantErr     bool
+	}{
+		{"all categories", nil, []string{""}, []string{"d1", "d2"}, false},
+		{"queried by category", []any{"Reviews"}, []string{"C1"}, []string{"d1"}, false},
+		{"each category", []any{"Ideas", "Reviews"}, []string{"C2", "C1"}, []string{"d2", "d1"}, false},
+		{"unknown category", []any{"Reviews", "Q&A"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeGitHub{discussions: map[string]string{
+				"":   discussion("d1", "Reviews") + "," + discussion("d2", "Ideas"),
+				"C1": discussion("d1", "Reviews"),
+				"C2": discussion("d2", "Ideas"),
+			}}
+			srv := httptest.NewServer(f)
+			defer srv.Close()
+
+			t.Setenv("TEST_GITHUB_TOKEN", "secret")
+			meta := map[any]any{
+				"repository_owner": "o",
+				"repository_name":  "r",
+				"api_token_env":    "TEST_GITHUB_TOKEN",
+				"api_endpoint":     srv.URL,
+			}
+			if tt.categories != nil {
+				meta["categories"] = tt.categories
+			}
+			config := reviewhub.RetrieverConfig{Name: "discussions", Type: "github-discussions", MetaData: meta}
+			users := []reviewhub.User{
+				{Name: "alice", MetaData: map[any]any{"github_id": "alice"}},
+				{Name: "bob", MetaData: map[any]any{"github_id": "bob"}},
+			}
+
+			l, err := new(GitHubDiscussionsRetriever).Retrieve(context.Background(), config, users)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Retrieve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(f.queried, tt.wantQueried) {
+				t.Errorf("queried categories %q, want %q", f.queried, tt.wantQueried)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []string
+			for _, p := range l.Pages {
+				got = append(got, p.ID)
+			}
+			if !slices.Equal(got, tt.wantPages) {
+				t.Errorf("pages = %v, want %v", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestRetrieveApprovalPages(t *testing.T) {
+	// approvals are on the second page of comments and the third page of reactions
+	d := `{"id":"d1","title":"d1","url":"https://github.com/o/r/discussions/1",
+		"createdAt":"2026-10-01T00:00:00Z","updatedAt":"2026-10-01T00:00:00Z",
+		"author":{"login":"alice"},"category":{"name":"Reviews"},"labels":{"nodes":[]},
+		"comments":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[{"body":"nice","author":{"login":"carol"}}]},
+		"reactions":{"pageInfo":{"hasNextPage":true,"endCursor":"r1"},"nodes":[{"content":"EYES","user":{"login":"bob"}}]}}`
+	srv := httptest.NewServer(&fakeGitHub{discussions: map[string]string{"": d}})
+	defer srv.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	config := reviewhub.RetrieverConfig{
+		Name: "discussions",
+		Type: "github-discussions",
+		MetaData: map[any]any{
+			"repository_owner": "o",
+			"repository_name":  "r",
+			"api_token_env":    "TEST_GITHUB_TOKEN",
+			"api_endpoint":     srv.URL,
+			"approval":         map[any]any{"keywords": []any{"lgtm"}, "reactions": []any{"+1"}},
+		},
+	}
+	var users []reviewhub.User
+	for _, name := range []string{"alice", "bob", "carol"} {
+		users = append(users, reviewhub.User{Name: name, MetaData: map[any]any{"github_id": name}})
+	}
+
+	l, err := new(GitHubDiscussionsRetriever).Retrieve(context.Background(), config, users)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(l.Pages) != 1 {
+		t.Fatalf("got %d pages, want 1", len(l.Pages))
+	}
+	var approved []string
+	for _, u := range l.Pages[0].ApprovedReviewers {
+		approved = append(approved, u.Name)
+	}
+	if !slices.Equal(approved, []string{"bob", "carol"}) {
+		t.Errorf("approved = %v, want bob by a comment and carol by a reaction", approved)
+	}
+}
@@ -0,0 +1,157 @@
+package ghpullrequests
+
+import (
+	"context"
+	"time"
+
+	"github.com/ry023/reviewhub/internal/github"
+)
+
+type pullRequest struct {
+	title              string
+	url                string
+	isDraft            bool
+	authorLogin        string
+	requestedReviewers []string
+	reviewedReviewers  []string // authors of latest reviews in any state
+	approvedReviewers  []string
+	createdAt          time.Time
+	updatedAt          time.Time
+	requestedAt        time.Time // earliest review request
+}
+
+const reviewStateApproved = "APPROVED"
+
+const pullRequestsQuery = `
+query($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(states: OPEN, first: 100, after: $after) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+      nodes {
+        title
+        url
+        isDraft
+        createdAt
+        updatedAt
+        author {
+          login
+        }
+        reviewRequests(first: 100) {
+          nodes {
+            requestedReviewer {
+              ... on User {
+                login
+              }
+            }
+          }
+        }
+        timelineItems(itemTypes: REVIEW_REQUESTED_EVENT, last: 100) {
+          nodes {
+            ... on ReviewRequestedEvent {
+              createdAt
+            }
+          }
+        }
+        latestReviews(first: 100) {
+          nodes {
+            state
+            author {
+              login
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+type pullRequestsResponse struct {
+	Repository struct {
+		PullRequests struct {
+			PageInfo github.PageInfo `json:"pageInfo"`
+			Nodes    []struct {
+				Title          string       `json:"title"`
+				Url            string       `json:"url"`
+				IsDraft        bool         `json:"isDraft"`
+				CreatedAt      time.Time    `json:"createdAt"`
+				UpdatedAt      time.Time    `json:"updatedAt"`
+				Author         github.Actor `json:"author"`
+				ReviewRequests struct {
+					Nodes []struct {
+						// login is empty for team review requests
+						RequestedReviewer github.Actor `json:"requestedReviewer"`
+					} `json:"nodes"`
+				} `json:"reviewRequests"`
+				TimelineItems struct {
+					Nodes []struct {
+						CreatedAt time.Time `json:"createdAt"`
+					} `json:"nodes"`
+				} `json:"timelineItems"`
+				LatestReviews struct {
+					Nodes []struct {
+						State  string       `json:"state"`
+						Author github.Actor `json:"author"`
+					} `json:"nodes"`
+				} `json:"latestReviews"`
+			} `json:"nodes"`
+		} `json:"pullRequests"`
+	} `json:"repository"`
+}
+
+// listPullRequests fetches open pull requests over all pages
+func listPullRequests(ctx context.Context, cli *github.Client, repositoryOwner, repository string) ([]pullRequest, error) {
+	var prs []pullRequest
+
+	vars := map[string]any{
+		"owner": repositoryOwner,
+		"name":  repository,
+	}
+	more := true
+	for more {
+		var res pullRequestsResponse
+		if err := cli.Query(ctx, pullRequestsQuery, vars, &res); err != nil {
+			return nil, err
+		}
+
+		for _, n := range res.Repository.PullRequests.Nodes {
+			pr := pullRequest{
+				title:       n.Title,
+				url:         n.Url,
+				isDraft:     n.IsDraft,
+				authorLogin: n.Author.Login,
+				createdAt:   n.CreatedAt,
+				updatedAt:   n.UpdatedAt,
+			}
+			for _, r := range n.ReviewRequests.Nodes {
+				if r.RequestedReviewer.Login != "" {
+					pr.requestedReviewers = append(pr.requestedReviewers, r.RequestedReviewer.Login)
+				}
+			}
+			for _, r := range n.LatestReviews.Nodes {
+				if r.Author.Login == "" || r.Author.Login == n.Author.Login {
+					continue
+				}
+				pr.reviewedReviewers = append(pr.reviewedReviewers, r.Author.Login)
+				if r.State == reviewStateApproved {
+					pr.approvedReviewers = append(pr.approvedReviewers, r.Author.Login)
+				}
+			}
+			for _, e := range n.TimelineItems.Nodes {
+				if !e.CreatedAt.IsZero() && (pr.requestedAt.IsZero() || e.CreatedAt.Before(pr.requestedAt)) {
+					pr.requestedAt = e.CreatedAt
+				}
+			}
+			prs = append(prs, pr)
+		}
+
+		info := res.Repository.PullRequests.PageInfo
+		more = info.HasNextPage
+		vars["after"] = info.EndCursor
+	}
+
+	return prs, nil
+}
@@ -0,0 +1,98 @@
+package ghpullrequests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ry023/reviewhub/internal/github"
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+type GitHubPullRequestsRetriever struct {
+}
+
+func init() {
+	reviewhub.RegisterRetriever("github-pull-requests", func() reviewhub.Retriever { return new(GitHubPullRequestsRetriever) })
+}
+
+type MetaData struct {
+	// Repositories in "owner/name" format
+	Repositories  []string `yaml:"repositories" validate:"required"`
+	ApiTokenEnv   string   `yaml:"api_token_env" validate:"required"`
+	ApiEndpoint   string   `yaml:"api_endpoint"`
+	IncludeDrafts bool     `yaml:"include_drafts"`
+}
+
+type UserMetaData struct {
+	GitHubId string `yaml:"github_id"`
+}
+
+func (p *GitHubPullRequestsRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := github.NewClient(meta.ApiEndpoint, os.Getenv(meta.ApiTokenEnv))
+
+	l := []reviewhub.ReviewPage{}
+	for _, repo := range meta.Repositories {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || owner == "" || name == "" {
+			return nil, fmt.Errorf("Invalid repository format (want owner/name): %s", repo)
+		}
+
+		prs, err := listPullRequests(ctx, cli, owner, name)
+		if err != nil {
+			err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "repository "+repo)
+			return nil, fmt.Errorf("Failed to request pull requests of %s: %w", repo, err)
+		}
+
+		for _, pr := range prs {
+			if pr.isDraft && !meta.IncludeDrafts {
+				continue
+			}
+
+			author := findUser(pr.authorLogin, knownUsers)
+			if author == nil {
+				author = reviewhub.NewUnknownUser(pr.authorLogin)
+			}
+
+			// users who reviewed in any state are dropped from review requests by GitHub, so both are reviewers
+			var reviewers, approved []reviewhub.User
+			for _, login := range append(pr.requestedReviewers, pr.reviewedReviewers...) {
+				if u := findUser(login, knownUsers); u != nil && !reviewhub.Contains(reviewers, *u) {
+					reviewers = append(reviewers, *u)
+				}
+			}
+			for _, login := range pr.approvedReviewers {
+				if u := findUser(login, knownUsers); u != nil {
+					approved = append(approved, *u)
+				}
+			}
+
+			if len(reviewers) == 0 {
+				// skip if no known reviewer
+				continue
+			}
+
+			page := reviewhub.NewReviewPage(pr.title, pr.url, *author, approved, reviewers)
+			page.CreatedAt = pr.createdAt
+			page.UpdatedAt = pr.updatedAt
+			page.RequestedAt = pr.requestedAt
+			l = append(l, page)
+		}
+	}
+
+	return &reviewhub.ReviewList{
+		Name:  config.Name,
+		Pages: l,
+	}, nil
+}
+
+// findUser searches known users by github_id metadata or name
+func findUser(login string, knownUsers []reviewhub.User) *reviewhub.User {
+	return reviewhub.FindUser(knownUsers, login, func(m UserMetaData) string { return m.GitHubId })
+}
@@ -0,0 +1,107 @@
+package ghpullrequests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestRetrieve(t *testing.T) {
+	pages := map[string]string{
+		"": `{"data":{"repository":{"pullRequests":{
+			"pageInfo":{"hasNextPage":true,"endCursor":"c1"},
+			"nodes":[{
+				"title":"first","url":"https://github.com/o/r/pull/1","isDraft":false,
+				"createdAt":"2026-10-01T00:00:00Z","updatedAt":"2026-10-02T00:00:00Z",
+				"author":{"login":"erin"},
+				"reviewRequests":{"nodes":[{"requestedReviewer":{"login":"carol"}},{"requestedReviewer":{}}]},
+				"timelineItems":{"nodes":[]},
+				"latestReviews":{"nodes":[
+					{"state":"APPROVED","author":{"login":"alice"}},
+					{"state":"CHANGES_REQUESTED","author":{"login":"bob"}},
+					{"state":"COMMENTED","author":{"login":"erin"}},
+					{"state":"COMMENTED","author":{"login":"stranger"}}
+				]}
+			}]}}}}`,
+		"c1": `{"data":{"repository":{"pullRequests":{
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"},
+			"nodes":[{
+				"title":"second","url":"https://github.com/o/r/pull/2","isDraft":false,
+				"createdAt":"2026-10-01T00:00:00Z","updatedAt":"2026-10-02T00:00:00Z",
+				"author":{"login":"alice"},
+				"reviewRequests":{"nodes":[]},
+				"timelineItems":{"nodes":[]},
+				"latestReviews":{"nodes":[{"state":"COMMENTED","author":{"login":"bob"}}]}
+			}]}}}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Invalid request body: %v", err)
+		}
+		after, _ := body.Variables["after"].(string)
+		fmt.Fprint(w, pages[after])
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	config := reviewhub.RetrieverConfig{
+		Name: "prs",
+		Type: "github-pull-requests",
+		MetaData: map[any]any{
+			"repositories":  []any{"o/r"},
+			"api_token_env": "TEST_GITHUB_TOKEN",
+			"api_endpoint":  srv.URL,
+		},
+	}
+	var users []reviewhub.User
+	for _, name := range []string{"alice", "bob", "carol", "erin"} {
+		users = append(users, reviewhub.User{Name: name, MetaData: map[any]any{"github_id": name}})
+	}
+
+	l, err := new(GitHubPullRequestsRetriever).Retrieve(context.Background(), config, users)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(l.Pages) != 2 {
+		t.Fatalf("got %d pages, want 2 over both result pages", len(l.Pages))
+	}
+
+	tests := []struct {
+		title         string
+		wantReviewers []string
+		wantApproved  []string
+	}{
+		// commenting or requesting changes drops users from review requests, but they still review
+		{"first", []string{"carol", "alice", "bob"}, []string{"alice"}},
+		{"second", []string{"bob"}, nil},
+	}
+	for i, tt := range tests {
+		page := l.Pages[i]
+		if page.Title != tt.title {
+			t.Errorf("page %d title = %q, want %q", i, page.Title, tt.title)
+		}
+		if got := names(page.Reviewers); !slices.Equal(got, tt.wantReviewers) {
+			t.Errorf("%s reviewers = %v, want %v", tt.title, got, tt.wantReviewers)
+		}
+		if got := names(page.ApprovedReviewers); !slices.Equal(got, tt.wantApproved) {
+			t.Errorf("%s approved = %v, want %v", tt.title, got, tt.wantApproved)
+		}
+	}
+}
+
+func names(users []reviewhub.User) []string {
+	var s []string
+	for _, u := range users {
+		s = append(s, u.Name)
+	}
+	return s
+}
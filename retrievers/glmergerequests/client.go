@@ -0,0 +1,130 @@
+package glmergerequests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ry023/reviewhub/internal/httpclient"
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+type user struct {
+	Username string `json:"username"`
+}
+
+type mergeRequest struct {
+	IID       int       `json:"iid"`
+	ProjectID int       `json:"project_id"`
+	Title     string    `json:"title"`
+	WebURL    string    `json:"web_url"`
+	Draft     bool      `json:"draft"`
+	Author    user      `json:"author"`
+	Reviewers []user    `json:"reviewers"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type approvals struct {
+	ApprovedBy []struct {
+		User user `json:"user"`
+	} `json:"approved_by"`
+}
+
+var httpClient = &httpclient.Client{}
+
+type client struct {
+	baseUrl string
+	token   string
+}
+
+// listMergeRequests lists opened merge requests of a project or a group
+func (c *client) listMergeRequests(ctx context.Context, scope, id string) ([]mergeRequest, error) {
+	var mrs []mergeRequest
+
+	page := "1"
+	for page != "" {
+		q := url.Values{}
+		q.Set("state", "opened")
+		q.Set("per_page", "100")
+		q.Set("page", page)
+		path := fmt.Sprintf("/%s/%s/merge_requests?%s", scope, url.PathEscape(id), q.Encode())
+
+		var res []mergeRequest
+		header, err := c.get(ctx, path, &res)
+		if err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, res...)
+
+		// GitLab sets empty X-Next-Page on the last page
+		page = header.Get("X-Next-Page")
+	}
+
+	return mrs, nil
+}
+
+func (c *client) getApprovals(ctx context.Context, projectId, iid int) (*approvals, error) {
+	path := fmt.Sprintf("/projects/%d/merge_requests/%d/approvals", projectId, iid)
+
+	// decode into a value, as a null body leaves a pointer nil
+	var res approvals
+	if _, err := c.get(ctx, path, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *client) get(ctx context.Context, path string, out any) (http.Header, error) {
+	// build request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+"/api/v4"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	// request
+	resBody, header, err := httpClient.Do(req)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	// parse response body
+	if err := json.Unmarshal(resBody, out); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// apiError converts error responses to reviewhub.APIError
+func apiError(err error) error {
+	var se *httpclient.StatusError
+	if !errors.As(err, &se) {
+		return err
+	}
+
+	// GitLab responds either {"message": ...} or {"error": ...}
+	var body struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	message := string(se.Body)
+	if err := json.Unmarshal(se.Body, &body); err == nil {
+		if body.Message != nil {
+			message = fmt.Sprint(body.Message)
+		} else if body.Error != "" {
+			message = body.Error
+		}
+	}
+
+	return &reviewhub.APIError{
+		Service:    "GitLab",
+		StatusCode: se.StatusCode,
+		Message:    message,
+		Kind:       reviewhub.KindOfStatus(se.StatusCode),
+	}
+}
@@ -0,0 +1,114 @@
+package glmergerequests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+type GitLabMergeRequestsRetriever struct {
+}
+
+func init() {
+	reviewhub.RegisterRetriever("gitlab-merge-requests", func() reviewhub.Retriever { return new(GitLabMergeRequestsRetriever) })
+}
+
+type MetaData struct {
+	BaseUrl       string `yaml:"base_url"`
+	ApiTokenEnv   string `yaml:"api_token_env" validate:"required"`
+	Project       string `yaml:"project"`
+	Group         string `yaml:"group"`
+	IncludeDrafts bool   `yaml:"include_drafts"`
+}
+
+type UserMetaData struct {
+	GitLabUsername string `yaml:"gitlab_username"`
+}
+
+const defaultBaseUrl = "https://gitlab.com"
+
+func (p *GitLabMergeRequestsRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return nil, err
+	}
+	if (meta.Project == "") == (meta.Group == "") {
+		return nil, fmt.Errorf("Either project or group required")
+	}
+
+	baseUrl := defaultBaseUrl
+	if meta.BaseUrl != "" {
+		baseUrl = strings.TrimSuffix(meta.BaseUrl, "/")
+	}
+	cli := &client{
+		baseUrl: baseUrl,
+		token:   os.Getenv(meta.ApiTokenEnv),
+	}
+
+	var mrs []mergeRequest
+	var resource string
+	if meta.Project != "" {
+		resource = "project " + meta.Project
+		mrs, err = cli.listMergeRequests(ctx, "projects", meta.Project)
+	} else {
+		resource = "group " + meta.Group
+		mrs, err = cli.listMergeRequests(ctx, "groups", meta.Group)
+	}
+	if err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, resource)
+		return nil, fmt.Errorf("Failed to list merge requests: %w", err)
+	}
+
+	l := []reviewhub.ReviewPage{}
+	for _, mr := range mrs {
+		if mr.Draft && !meta.IncludeDrafts {
+			continue
+		}
+
+		var reviewers []reviewhub.User
+		for _, r := range mr.Reviewers {
+			if u := findUser(r.Username, knownUsers); u != nil {
+				reviewers = append(reviewers, *u)
+			}
+		}
+		if len(reviewers) == 0 {
+			// skip if no known reviewer
+			continue
+		}
+
+		a, err := cli.getApprovals(ctx, mr.ProjectID, mr.IID)
+		if err != nil {
+			err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, resource)
+			return nil, fmt.Errorf("Failed to get approvals of %s: %w", mr.WebURL, err)
+		}
+		var approved []reviewhub.User
+		for _, v := range a.ApprovedBy {
+			if u := findUser(v.User.Username, knownUsers); u != nil {
+				approved = append(approved, *u)
+			}
+		}
+
+		author := findUser(mr.Author.Username, knownUsers)
+		if author == nil {
+			author = reviewhub.NewUnknownUser(mr.Author.Username)
+		}
+
+		page := reviewhub.NewReviewPage(mr.Title, mr.WebURL, *author, approved, reviewers)
+		page.CreatedAt = mr.CreatedAt
+		page.UpdatedAt = mr.UpdatedAt
+		l = append(l, page)
+	}
+
+	return &reviewhub.ReviewList{
+		Name:  config.Name,
+		Pages: l,
+	}, nil
+}
+
+// findUser searches known users by gitlab_username metadata or name
+func findUser(username string, knownUsers []reviewhub.User) *reviewhub.User {
+	return reviewhub.FindUser(knownUsers, username, func(m UserMetaData) string { return m.GitLabUsername })
+}
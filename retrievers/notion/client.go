@@ -2,17 +2,43 @@ package notion
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/buger/jsonparser"
+	"github.com/ry023/reviewhub/internal/httpclient"
 	"github.com/ry023/reviewhub/reviewhub"
 )
 
-const apiEndpoint = "https://api.notion.com/v1"
+const defaultApiEndpoint = "https://api.notion.com/v1"
+
+// Notion allows 3 requests per second in average
+var client = &httpclient.Client{MinInterval: time.Second / 3}
+
+// api calls Notion API at endpoint with token
+type api struct {
+	endpoint string
+	token    string
+}
+
+func newAPI(meta *MetaData) *api {
+	endpoint := meta.ApiEndpoint
+	if endpoint == "" {
+		endpoint = defaultApiEndpoint
+	}
+	return &api{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    os.Getenv(meta.ApiTokenEnv),
+	}
+}
 
 type queryParam struct {
 	Filter      any    `json:"filter,omitempty"`
@@ -32,11 +58,31 @@ func (p jsonPage) title(prop string) (string, error) {
 	return jsonparser.GetString(p, "properties", prop, "title", "[0]", "text", "content")
 }
 
+func (p jsonPage) id() (string, error) {
+	return jsonparser.GetString(p, "id")
+}
+
 func (p jsonPage) url() (string, error) {
 	// TODO: parse richtext strictly
 	return jsonparser.GetString(p, "url")
 }
 
+func (p jsonPage) createdTime() (time.Time, error) {
+	return p.timestamp("created_time")
+}
+
+func (p jsonPage) lastEditedTime() (time.Time, error) {
+	return p.timestamp("last_edited_time")
+}
+
+func (p jsonPage) timestamp(key string) (time.Time, error) {
+	s, err := jsonparser.GetString(p, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
 func (p jsonPage) owner(prop string, knownUsers []reviewhub.User) (*reviewhub.User, error) {
 	propid, err := jsonparser.GetString(p, "properties", prop, "people", "[0]", "id")
 	if err != nil {
@@ -65,6 +111,32 @@ func (p jsonPage) owner(prop string, knownUsers []reviewhub.User) (*reviewhub.Us
 }
 
 func (p jsonPage) peopleProp(prop string, knownUsers []reviewhub.User) ([]reviewhub.User, error) {
+	peopleIds, err := p.peopleIds(prop)
+	if err != nil {
+		return nil, err
+	}
+
+	// search in known user
+	var people []reviewhub.User
+	for _, propid := range peopleIds {
+		for _, u := range knownUsers {
+			meta, err := reviewhub.ParseMetaData[UserMetaData](u.MetaData)
+			if err != nil {
+				log.Printf("Skip user %s because it may not have notion metadata: %v", u.Name, err)
+				continue
+			}
+
+			if meta.NotionId == propid {
+				people = append(people, u)
+			}
+		}
+	}
+
+	return people, nil
+}
+
+// peopleIds returns ids of people in the property including unknown users
+func (p jsonPage) peopleIds(prop string) ([]string, error) {
 	var peopleIds []string
 	// parse properties
 	_, err := jsonparser.ArrayEach(
@@ -93,26 +165,10 @@ func (p jsonPage) peopleProp(prop string, knownUsers []reviewhub.User) ([]review
 		return nil, err
 	}
 
-	// search in known user
-	var people []reviewhub.User
-	for _, propid := range peopleIds {
-		for _, u := range knownUsers {
-			meta, err := reviewhub.ParseMetaData[UserMetaData](u.MetaData)
-			if err != nil {
-				log.Printf("Skip user %s because it may not have notion metadata: %v", u.Name, err)
-				continue
-			}
-
-			if meta.NotionId == propid {
-				people = append(people, u)
-			}
-		}
-	}
-
-	return people, nil
+	return peopleIds, nil
 }
 
-func queryDatabase(databaseId, filterJSON, token string) ([]jsonPage, error) {
+func (a *api) queryDatabase(ctx context.Context, databaseId, filterJSON string) ([]jsonPage, error) {
 	var pages []jsonPage
 
 	var filter any
@@ -127,7 +183,7 @@ func queryDatabase(databaseId, filterJSON, token string) ([]jsonPage, error) {
 			Filter:      filter,
 			StartCursor: cur,
 		}
-		res, err := request(databaseId, token, p)
+		res, err := a.request(ctx, databaseId, p)
 		if err != nil {
 			return nil, err
 		}
@@ -149,42 +205,133 @@ func queryDatabase(databaseId, filterJSON, token string) ([]jsonPage, error) {
 	return pages, nil
 }
 
-func request(databaseId, token string, param queryParam) (*response, error) {
-	// build request body bytes
-	p, err := json.Marshal(param)
+func (a *api) request(ctx context.Context, databaseId string, param queryParam) (*response, error) {
+	// querying is read only, so safe to retry in spite of POST
+	resBody, err := a.do(httpclient.Idempotent(ctx), http.MethodPost, fmt.Sprintf("/databases/%s/query", databaseId), param)
 	if err != nil {
 		return nil, err
 	}
-	reqBody := bytes.NewBuffer(p)
+
+	// parse response body
+	var res *response
+	if err := json.Unmarshal(resBody, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type peopleValue struct {
+	People []personRef `json:"people"`
+}
+
+type personRef struct {
+	Object string `json:"object"`
+	Id     string `json:"id"`
+}
+
+// updatePeopleProp replaces people of the property of the page
+func (a *api) updatePeopleProp(ctx context.Context, pageId, prop string, peopleIds []string) error {
+	people := []personRef{}
+	for _, id := range peopleIds {
+		people = append(people, personRef{Object: "user", Id: id})
+	}
+	body := map[string]any{
+		"properties": map[string]peopleValue{
+			prop: {People: people},
+		},
+	}
+
+	_, err := a.do(ctx, http.MethodPatch, fmt.Sprintf("/pages/%s", pageId), body)
+	return err
+}
+
+type richText struct {
+	Type    string       `json:"type"`
+	Text    *textValue   `json:"text,omitempty"`
+	Mention *mentionUser `json:"mention,omitempty"`
+}
+
+type textValue struct {
+	Content string `json:"content"`
+}
+
+type mentionUser struct {
+	Type string    `json:"type"`
+	User personRef `json:"user"`
+}
+
+// createComment posts a comment of the message followed by mentions of people to the page
+func (a *api) createComment(ctx context.Context, pageId, message string, peopleIds []string) error {
+	texts := []richText{{Type: "text", Text: &textValue{Content: message + " "}}}
+	for _, id := range peopleIds {
+		texts = append(texts,
+			richText{Type: "mention", Mention: &mentionUser{Type: "user", User: personRef{Object: "user", Id: id}}},
+			richText{Type: "text", Text: &textValue{Content: " "}},
+		)
+	}
+	body := map[string]any{
+		"parent":    map[string]string{"page_id": pageId},
+		"rich_text": texts,
+	}
+
+	_, err := a.do(ctx, http.MethodPost, "/comments", body)
+	return err
+}
+
+// getPage retrieves the page by id
+func (a *api) getPage(ctx context.Context, pageId string) (jsonPage, error) {
+	return a.do(ctx, http.MethodGet, fmt.Sprintf("/pages/%s", pageId), nil)
+}
+
+func (a *api) do(ctx context.Context, method, path string, body any) ([]byte, error) {
+	// build request body bytes
+	var reqBody io.Reader
+	if body != nil {
+		p, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(p)
+	}
 
 	// build request
-	url := fmt.Sprintf("%s/databases/%s/query", apiEndpoint, databaseId)
-	req, err := http.NewRequest("POST", url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, a.endpoint+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to query to api: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Authorization", "Bearer "+a.token)
 	req.Header.Set("Notion-Version", "2022-06-28")
 	req.Header.Set("Content-Type", "application/json")
 
 	// request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resBody, _, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, apiError(err)
 	}
-	defer resp.Body.Close()
+	return resBody, nil
+}
 
-	// read body
-	resBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// apiError converts error responses to reviewhub.APIError
+func apiError(err error) error {
+	var se *httpclient.StatusError
+	if !errors.As(err, &se) {
+		return err
 	}
 
-	// parse response body
-	var res *response
-	if err := json.Unmarshal(resBody, &res); err != nil {
-		return nil, err
+	// https://developers.notion.com/reference/status-codes
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(se.Body, &body); err != nil || body.Message == "" {
+		body.Message = string(se.Body)
+	}
+
+	return &reviewhub.APIError{
+		Service:    "Notion",
+		StatusCode: se.StatusCode,
+		Code:       body.Code,
+		Message:    body.Message,
+		Kind:       reviewhub.KindOfStatus(se.StatusCode),
 	}
-	return res, nil
 }
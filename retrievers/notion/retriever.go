@@ -1,8 +1,10 @@
 package notion
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"slices"
+	"sync"
 
 	"github.com/ry023/reviewhub/reviewhub"
 )
@@ -10,8 +12,13 @@ import (
 type NotionRetriever struct {
 }
 
+func init() {
+	reviewhub.RegisterRetriever("notion", func() reviewhub.Retriever { return new(NotionRetriever) })
+}
+
 type MetaData struct {
 	ApiTokenEnv           string   `yaml:"api_token_env" validate:"required"`
+	ApiEndpoint           string   `yaml:"api_endpoint"`
 	DatabaseId            string   `yaml:"database_id" validate:"required"`
 	OwnerProperty         string   `yaml:"owner_property" validate:"required"`
 	ApprovedUsersProperty string   `yaml:"approved_users_property" validate:"required"`
@@ -25,7 +32,7 @@ type UserMetaData struct {
 	NotionId string `yaml:"notion_id"`
 }
 
-func (p *NotionRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
+func (p *NotionRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, knownUsers []reviewhub.User) (*reviewhub.ReviewList, error) {
 	// parse config
 	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
 	if err != nil {
@@ -35,12 +42,15 @@ func (p *NotionRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers
 		return nil, fmt.Errorf("Either static_reviewers or reviewers_property required")
 	}
 
-	token := os.Getenv(meta.ApiTokenEnv)
-	pages, err := queryDatabase(meta.DatabaseId, meta.Filter, token)
+	pages, err := newAPI(meta).queryDatabase(ctx, meta.DatabaseId, meta.Filter)
 	if err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "database "+meta.DatabaseId)
 		return nil, fmt.Errorf("Failed to query database: %w", err)
 	}
 
+	// static_reviewers may have group names
+	staticReviewers := config.Groups.Expand(meta.StaticReviewers)
+
 	// Convert to ReviewPage format
 	var reviewPages []reviewhub.ReviewPage
 	for _, page := range pages {
@@ -66,7 +76,7 @@ func (p *NotionRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers
 		var reviewers []reviewhub.User
 		if meta.StaticReviewers != nil && len(meta.StaticReviewers) > 0 {
 			for _, u := range knownUsers {
-				for _, n := range meta.StaticReviewers {
+				for _, n := range staticReviewers {
 					if u.Name == n && u.Name != owner.Name {
 						reviewers = append(reviewers, u)
 					}
@@ -86,10 +96,18 @@ func (p *NotionRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers
 		}
 
 		reviewPage := reviewhub.NewReviewPage(title, url, owner, approvedUsers, reviewers)
-
-		if len(reviewPage.ApprovedReviewers) < len(reviewPage.Reviewers) {
-			reviewPages = append(reviewPages, reviewPage)
+		if id, err := page.id(); err == nil {
+			reviewPage.ID = id
 		}
+		if t, err := page.createdTime(); err == nil {
+			reviewPage.CreatedAt = t
+		}
+		if t, err := page.lastEditedTime(); err == nil {
+			reviewPage.UpdatedAt = t
+		}
+
+		// whether the page is done is decided by approval rules of the retriever config
+		reviewPages = append(reviewPages, reviewPage)
 	}
 
 	return &reviewhub.ReviewList{
@@ -97,3 +115,134 @@ func (p *NotionRetriever) Retrieve(config reviewhub.RetrieverConfig, knownUsers
 		Pages: reviewPages,
 	}, nil
 }
+
+// AssignReviewers writes assigned reviewers to reviewers_property of the page if it is still empty
+func (p *NotionRetriever) AssignReviewers(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, reviewers []reviewhub.User) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if meta.ReviewersProperty == "" {
+		return fmt.Errorf("reviewers_property required to write back reviewers")
+	}
+	if page.ID == "" {
+		return fmt.Errorf("Page id of %s unknown", page.Url)
+	}
+
+	ids, err := notionIds(reviewers)
+	if err != nil {
+		return err
+	}
+
+	// reviewers unknown to reviewhub are dropped from the page, so see the property itself
+	a := newAPI(meta)
+	current, err := a.getPage(ctx, page.ID)
+	if err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "page "+page.ID)
+		return fmt.Errorf("Failed to get page: %w", err)
+	}
+	assigned, err := current.peopleIds(meta.ReviewersProperty)
+	if err != nil {
+		return fmt.Errorf("Failed to parse reviewers_property (%s): %w", meta.ReviewersProperty, err)
+	}
+	if len(assigned) > 0 {
+		return reviewhub.ErrAlreadyAssigned
+	}
+
+	if err := a.updatePeopleProp(ctx, page.ID, meta.ReviewersProperty, ids); err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "page "+page.ID)
+		return fmt.Errorf("Failed to update reviewers_property (%s): %w", meta.ReviewersProperty, err)
+	}
+	return nil
+}
+
+// ValidateReviewers checks users have notion_id to be written back
+func (p *NotionRetriever) ValidateReviewers(users []reviewhub.User) error {
+	_, err := notionIds(users)
+	return err
+}
+
+// Comment posts the message mentioning users to the page, which needs the comment capability of the integration
+func (p *NotionRetriever) Comment(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, message string, mentions []reviewhub.User) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if page.ID == "" {
+		return fmt.Errorf("Page id of %s unknown", page.Url)
+	}
+
+	ids, err := notionIds(mentions)
+	if err != nil {
+		return err
+	}
+
+	if err := newAPI(meta).createComment(ctx, page.ID, message, ids); err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "page "+page.ID)
+		return fmt.Errorf("Failed to comment: %w", err)
+	}
+	return nil
+}
+
+// Approve adds the user to approved_users_property of the page
+func (p *NotionRetriever) Approve(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, user reviewhub.User) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+	if page.ID == "" {
+		return fmt.Errorf("Page id of %s unknown", page.Url)
+	}
+
+	ids, err := notionIds([]reviewhub.User{user})
+	if err != nil {
+		return err
+	}
+
+	// concurrent approvals would drop each other between get and update
+	unlock := lockPage(page.ID)
+	defer unlock()
+
+	a := newAPI(meta)
+	current, err := a.getPage(ctx, page.ID)
+	if err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "page "+page.ID)
+		return fmt.Errorf("Failed to get page: %w", err)
+	}
+	approved, err := current.peopleIds(meta.ApprovedUsersProperty)
+	if err != nil {
+		return fmt.Errorf("Failed to parse approved_users_property (%s): %w", meta.ApprovedUsersProperty, err)
+	}
+	if slices.Contains(approved, ids[0]) {
+		// already approved
+		return nil
+	}
+
+	if err := a.updatePeopleProp(ctx, page.ID, meta.ApprovedUsersProperty, append(approved, ids[0])); err != nil {
+		err = reviewhub.AnnotateAPIError(err, meta.ApiTokenEnv, "page "+page.ID)
+		return fmt.Errorf("Failed to update approved_users_property (%s): %w", meta.ApprovedUsersProperty, err)
+	}
+	return nil
+}
+
+// pageLocks serializes updates by page ids, as Notion has no conditional updates
+var pageLocks sync.Map
+
+func lockPage(id string) func() {
+	v, _ := pageLocks.LoadOrStore(id, new(sync.Mutex))
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func notionIds(users []reviewhub.User) ([]string, error) {
+	var ids []string
+	for _, u := range users {
+		umeta, err := reviewhub.ParseMetaData[UserMetaData](u.MetaData)
+		if err != nil || umeta.NotionId == "" {
+			return nil, fmt.Errorf("User %s has no notion_id", u.Name)
+		}
+		ids = append(ids, umeta.NotionId)
+	}
+	return ids, nil
+}
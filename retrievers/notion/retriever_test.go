@@ -0,0 +1,249 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestApprove(t *testing.T) {
+	tests := []struct {
+		name        string
+		approved    []string
+		wantPatched []string
+	}{
+		{"add to approved", []string{"u-bob"}, []string{"u-bob", "u-alice"}},
+		{"first approval", nil, []string{"u-alice"}},
+		{"already approved", []string{"u-alice", "u-bob"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests []string
+			var patched []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+				}
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/v1/pages/page-1":
+					var people []string
+					for _, id := range tt.approved {
+						people = append(people, fmt.Sprintf(`{"object":"user","id":%q}`, id))
+					}
+					fmt.Fprintf(w, `{"object":"page","id":"page-1","properties":{"Approved":{"type":"people","people":[%s]}}}`,
+						strings.Join(people, ","))
+				case r.Method == http.MethodPatch && r.URL.Path == "/v1/pages/page-1":
+					b, _ := io.ReadAll(r.Body)
+					var body struct {
+						Properties map[string]peopleValue `json:"properties"`
+					}
+					if err := json.Unmarshal(b, &body); err != nil {
+						t.Errorf("Invalid PATCH body: %s", b)
+					}
+					for _, p := range body.Properties["Approved"].People {
+						patched = append(patched, p.Id)
+					}
+					if len(body.Properties) != 1 {
+						t.Errorf("PATCH properties = %s, want only Approved", b)
+					}
+					fmt.Fprint(w, `{"object":"page","id":"page-1"}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"object":"error","status":404,"code":"object_not_found","message":"not found"}`)
+				}
+			}))
+			defer srv.Close()
+
+			t.Setenv("TEST_NOTION_TOKEN", "secret")
+			config := reviewhub.RetrieverConfig{
+				Name: "notion",
+				Type: "notion",
+				MetaData: map[any]any{
+					"api_token_env":           "TEST_NOTION_TOKEN",
+					"api_endpoint":            srv.URL + "/v1",
+					"database_id":             "db",
+					"owner_property":          "Owner",
+					"approved_users_property": "Approved",
+					"title_property":          "Name",
+				},
+			}
+			page := reviewhub.ReviewPage{Page: reviewhub.Page{ID: "page-1", Url: "https://www.notion.so/page-1"}}
+			user := reviewhub.User{Name: "alice", MetaData: map[any]any{"notion_id": "u-alice"}}
+
+			if err := new(NotionRetriever).Approve(context.Background(), config, page, user); err != nil {
+				t.Fatalf("Approve() error = %v", err)
+			}
+
+			wantRequests := []string{"GET /v1/pages/page-1"}
+			if tt.wantPatched != nil {
+				wantRequests = append(wantRequests, "PATCH /v1/pages/page-1")
+			}
+			if !slices.Equal(requests, wantRequests) {
+				t.Errorf("requests = %v, want %v", requests, wantRequests)
+			}
+			if !slices.Equal(patched, tt.wantPatched) {
+				t.Errorf("patched people = %v, want %v", patched, tt.wantPatched)
+			}
+		})
+	}
+}
+
+func TestApproveConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var approved []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			mu.Lock()
+			var people []string
+			for _, id := range approved {
+				people = append(people, fmt.Sprintf(`{"object":"user","id":%q}`, id))
+			}
+			mu.Unlock()
+			// let other approvals get the same page
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprintf(w, `{"object":"page","id":"page-1","properties":{"Approved":{"type":"people","people":[%s]}}}`,
+				strings.Join(people, ","))
+		case http.MethodPatch:
+			var body struct {
+				Properties map[string]peopleValue `json:"properties"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			approved = nil
+			for _, p := range body.Properties["Approved"].People {
+				approved = append(approved, p.Id)
+			}
+			mu.Unlock()
+			fmt.Fprint(w, `{"object":"page","id":"page-1"}`)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_NOTION_TOKEN", "secret")
+	config := reviewhub.RetrieverConfig{
+		Name: "notion",
+		Type: "notion",
+		MetaData: map[any]any{
+			"api_token_env":           "TEST_NOTION_TOKEN",
+			"api_endpoint":            srv.URL + "/v1",
+			"database_id":             "db",
+			"owner_property":          "Owner",
+			"approved_users_property": "Approved",
+			"title_property":          "Name",
+		},
+	}
+	page := reviewhub.ReviewPage{Page: reviewhub.Page{ID: "page-1", Url: "https://www.notion.so/page-1"}}
+
+	names := []string{"alice", "bob", "carol"}
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			user := reviewhub.User{Name: name, MetaData: map[any]any{"notion_id": "u-" + name}}
+			if err := new(NotionRetriever).Approve(context.Background(), config, page, user); err != nil {
+				t.Errorf("Approve() of %s error = %v", name, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	slices.Sort(approved)
+	if want := []string{"u-alice", "u-bob", "u-carol"}; !slices.Equal(approved, want) {
+		t.Errorf("approved = %v, want all of %v", approved, want)
+	}
+}
+
+func TestAssignReviewers(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     []string // people in the reviewers property
+		reviewer    reviewhub.User
+		wantPatched []string
+		wantErr     error
+	}{
+		{"empty property", nil, reviewhub.User{Name: "alice", MetaData: map[any]any{"notion_id": "u-alice"}}, []string{"u-alice"}, nil},
+		// unknown to reviewhub, so the page has no reviewers
+		{"unknown reviewer", []string{"u-stranger"}, reviewhub.User{Name: "alice", MetaData: map[any]any{"notion_id": "u-alice"}}, nil, reviewhub.ErrAlreadyAssigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patched []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					var people []string
+					for _, id := range tt.current {
+						people = append(people, fmt.Sprintf(`{"object":"user","id":%q}`, id))
+					}
+					fmt.Fprintf(w, `{"object":"page","id":"page-1","properties":{"Reviewers":{"type":"people","people":[%s]}}}`,
+						strings.Join(people, ","))
+				case http.MethodPatch:
+					var body struct {
+						Properties map[string]peopleValue `json:"properties"`
+					}
+					json.NewDecoder(r.Body).Decode(&body)
+					for _, p := range body.Properties["Reviewers"].People {
+						patched = append(patched, p.Id)
+					}
+					fmt.Fprint(w, `{"object":"page","id":"page-1"}`)
+				}
+			}))
+			defer srv.Close()
+
+			t.Setenv("TEST_NOTION_TOKEN", "secret")
+			config := reviewhub.RetrieverConfig{
+				Name: "notion",
+				Type: "notion",
+				MetaData: map[any]any{
+					"api_token_env":           "TEST_NOTION_TOKEN",
+					"api_endpoint":            srv.URL + "/v1",
+					"database_id":             "db",
+					"owner_property":          "Owner",
+					"approved_users_property": "Approved",
+					"reviewers_property":      "Reviewers",
+					"title_property":          "Name",
+				},
+			}
+			page := reviewhub.ReviewPage{Page: reviewhub.Page{ID: "page-1", Url: "https://www.notion.so/page-1"}}
+
+			err := new(NotionRetriever).AssignReviewers(context.Background(), config, page, []reviewhub.User{tt.reviewer})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AssignReviewers() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(patched, tt.wantPatched) {
+				t.Errorf("patched reviewers = %v, want %v", patched, tt.wantPatched)
+			}
+		})
+	}
+}
+
+func TestValidateReviewers(t *testing.T) {
+	users := []reviewhub.User{
+		{Name: "alice", MetaData: map[any]any{"notion_id": "u-alice"}},
+		{Name: "bob"},
+	}
+	if err := new(NotionRetriever).ValidateReviewers(users[:1]); err != nil {
+		t.Errorf("ValidateReviewers() error = %v", err)
+	}
+	if err := new(NotionRetriever).ValidateReviewers(users); err == nil {
+		t.Error("ValidateReviewers() error = nil, want bob without notion_id")
+	}
+}
@@ -0,0 +1,51 @@
+package reviewhub
+
+// ApprovalRule requires Count approvals from users in From
+type ApprovalRule struct {
+	// From is user or group names whose approvals count, or reviewers of the page if empty
+	From []string `yaml:"from"`
+	// Count is the number of approvals required, or all users in From if zero
+	Count int `yaml:"count"`
+}
+
+// IsApproved reports whether the page satisfies all rules.
+// Without rules, all reviewers must approve.
+func (p ReviewPage) IsApproved(rules []ApprovalRule, groups Groups) bool {
+	if len(rules) == 0 {
+		rules = []ApprovalRule{{}}
+	}
+
+	for _, r := range rules {
+		var from []User
+		if len(r.From) == 0 {
+			from = p.Reviewers
+		} else {
+			for _, n := range groups.Expand(r.From) {
+				from = append(from, User{Name: n})
+			}
+		}
+
+		approved := 0
+		for _, u := range from {
+			if Contains(p.ApprovedReviewers, u) {
+				approved++
+			}
+		}
+
+		required := r.Count
+		if required <= 0 {
+			required = len(from)
+		}
+		if approved < required {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyApprovalRules marks pages satisfying rules as Done
+func ApplyApprovalRules(l *ReviewList, rules []ApprovalRule, groups Groups) {
+	for i, page := range l.Pages {
+		l.Pages[i].Done = page.IsApproved(rules, groups)
+	}
+}
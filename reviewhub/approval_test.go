@@ -0,0 +1,64 @@
+package reviewhub
+
+import "testing"
+
+func TestIsApproved(t *testing.T) {
+	users := func(names ...string) []User {
+		var us []User
+		for _, n := range names {
+			us = append(us, User{Name: n})
+		}
+		return us
+	}
+	groups := Groups{
+		"leads": {"alice", "bob"},
+		"team":  {"leads", "carol"},
+	}
+
+	tests := []struct {
+		name      string
+		reviewers []User
+		approved  []User
+		rules     []ApprovalRule
+		want      bool
+	}{
+		{"all reviewers approved", users("alice", "bob"), users("bob", "alice"), nil, true},
+		{"a reviewer pending", users("alice", "bob"), users("alice"), nil, false},
+		{"approvals of non-reviewers ignored", users("alice"), users("bob"), nil, false},
+		// nothing is required, so the page is done as soon as it is retrieved
+		{"no reviewers and no rules", nil, nil, nil, true},
+		{"quorum of reviewers", users("alice", "bob", "carol"), users("carol"), []ApprovalRule{{Count: 1}}, true},
+		{"quorum of reviewers not met", users("alice", "bob", "carol"), users("carol"), []ApprovalRule{{Count: 2}}, false},
+		{"quorum from a group", users("carol"), users("bob"), []ApprovalRule{{From: []string{"leads"}, Count: 1}}, true},
+		{"approvals outside the group ignored", users("carol"), users("carol"), []ApprovalRule{{From: []string{"leads"}, Count: 1}}, false},
+		{"all of nested groups", users("carol"), users("alice", "bob", "carol"), []ApprovalRule{{From: []string{"team"}}}, true},
+		{"all of nested groups pending", users("carol"), users("alice", "carol"), []ApprovalRule{{From: []string{"team"}}}, false},
+		{"every rule required", users("alice", "carol"), users("alice"), []ApprovalRule{{From: []string{"leads"}, Count: 1}, {From: []string{"carol"}}}, false},
+		{"count over members", users("alice"), users("alice"), []ApprovalRule{{From: []string{"alice"}, Count: 2}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := ReviewPage{Reviewers: tt.reviewers, ApprovedReviewers: tt.approved}
+			if got := page.IsApproved(tt.rules, groups); got != tt.want {
+				t.Errorf("IsApproved() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyApprovalRules(t *testing.T) {
+	l := &ReviewList{Pages: []ReviewPage{
+		{Page: Page{Url: "done"}, Reviewers: []User{{Name: "alice"}}, ApprovedReviewers: []User{{Name: "alice"}}},
+		{Page: Page{Url: "pending"}, Reviewers: []User{{Name: "alice"}}},
+		// marked done by a previous rule, but pending by these
+		{Page: Page{Url: "reopened"}, Reviewers: []User{{Name: "bob"}}, Done: true},
+	}}
+	ApplyApprovalRules(l, nil, nil)
+
+	want := map[string]bool{"done": true, "pending": false, "reopened": false}
+	for _, page := range l.Pages {
+		if page.Done != want[page.Url] {
+			t.Errorf("%s Done = %v, want %v", page.Url, page.Done, want[page.Url])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package reviewhub
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+const (
+	AssignmentRoundRobin  = "round_robin"
+	AssignmentLeastLoaded = "least_loaded"
+)
+
+// AssignmentConfig assigns reviewers to pages without reviewers
+type AssignmentConfig struct {
+	// From is user or group names to pick reviewers from
+	From []string `yaml:"from"`
+	// Count of reviewers per page, 1 if zero
+	Count int `yaml:"count"`
+	// Strategy is AssignmentRoundRobin or AssignmentLeastLoaded (default)
+	Strategy string `yaml:"strategy"`
+	// WriteBack stores assigned reviewers to the source if the retriever implements ReviewerAssigner.
+	// Without state, WriteBack is required and AssignmentRoundRobin is not available.
+	WriteBack bool `yaml:"write_back"`
+}
+
+// ReviewerAssigner is implemented by retrievers which can write assigned reviewers back to the source.
+// AssignReviewers must return ErrAlreadyAssigned instead of overwriting reviewers in the source,
+// which may be users unknown to reviewhub and so missing in the page.
+type ReviewerAssigner interface {
+	AssignReviewers(context.Context, RetrieverConfig, ReviewPage, []User) error
+}
+
+// ReviewerValidator is optionally implemented by ReviewerAssigner to check users can be written back before runs
+type ReviewerValidator interface {
+	ValidateReviewers([]User) error
+}
+
+var ErrAlreadyAssigned = errors.New("Page already has reviewers in the source")
+
+// Outstanding counts pages each user still needs to review: user name -> count
+func Outstanding(ls []ReviewList) map[string]int {
+	load := map[string]int{}
+	for _, l := range ls {
+		for _, page := range l.Pages {
+			if page.Done {
+				continue
+			}
+			for _, u := range page.Reviewers {
+				if !Contains(page.ApprovedReviewers, u) {
+					load[u.Name]++
+				}
+			}
+		}
+	}
+	return load
+}
+
+// PickReviewers picks reviewers from candidates except the owner by the strategy.
+// load is updated with picked users, and cursor is advanced for round robin.
+func PickReviewers(config AssignmentConfig, candidates []User, owner User, load map[string]int, cursor *int) []User {
+	var cs []User
+	for _, c := range candidates {
+		if c.Name != owner.Name {
+			cs = append(cs, c)
+		}
+	}
+	if len(cs) == 0 {
+		return nil
+	}
+
+	count := config.Count
+	if count <= 0 {
+		count = 1
+	}
+	count = min(count, len(cs))
+
+	var picked []User
+	switch config.Strategy {
+	case AssignmentRoundRobin:
+		// cursor is an index of candidates including the owner to stay fair across owners
+		for i := 0; len(picked) < count && i < len(candidates); i++ {
+			j := (*cursor + i) % len(candidates)
+			if c := candidates[j]; c.Name != owner.Name {
+				picked = append(picked, c)
+				*cursor = (j + 1) % len(candidates)
+			}
+		}
+	default:
+		// least outstanding reviews first, config order on ties
+		slices.SortStableFunc(cs, func(a, b User) int {
+			return load[a.Name] - load[b.Name]
+		})
+		picked = cs[:count]
+	}
+
+	for _, u := range picked {
+		load[u.Name]++
+	}
+	return picked
+}
@@ -2,6 +2,7 @@ package reviewhub
 
 import (
 	"os"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/go-yaml/yaml"
@@ -11,24 +12,65 @@ type Config struct {
 	Retrievers []RetrieverConfig `yaml:"retrievers"`
 	Notifiers  []NotifierConfig  `yaml:"notifiers"`
 	Users      []User            `yaml:"users"`
+	Groups     Groups            `yaml:"groups"`
+	State      *StateConfig      `yaml:"state"`
+
+	// Schedule is the default cron expression for notifiers in daemon mode
+	Schedule string `yaml:"schedule"`
+	// Timezone to evaluate schedules and days of notifiers in, local time if empty
+	Timezone string `yaml:"timezone"`
+	// Concurrency is the max number of retrievers running at once
+	Concurrency int `yaml:"concurrency"`
+	// FailurePolicy is either FailurePolicyFailFast (default) or FailurePolicyContinue
+	FailurePolicy string `yaml:"failure_policy"`
 }
 
+const (
+	// FailurePolicyFailFast aborts the run on the first error of non-optional retrievers,
+	// and skips the remaining users of a notifier or write backs of assignments on its first error
+	FailurePolicyFailFast = "fail_fast"
+	// FailurePolicyContinue notifies with failed sources reported, and returns errors after all
+	FailurePolicyContinue = "continue"
+)
+
 type MetaData any
 
 type Validator interface {
 	Validate() error
 }
 
+// Location returns the timezone of config, local time if not set
+func (c Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
 type NotifierConfig struct {
 	Name     string   `yaml:"name"`
 	Type     string   `yaml:"type"`
+	Schedule string   `yaml:"schedule"`
 	MetaData MetaData `yaml:"metadata"`
 }
 
 type RetrieverConfig struct {
-	Name     string   `yaml:"name"`
-	Type     string   `yaml:"type"`
-	MetaData MetaData `yaml:"metadata"`
+	Name     string     `yaml:"name"`
+	Type     string     `yaml:"type"`
+	SLA      *SLAConfig `yaml:"sla"`
+	Timeout  Duration   `yaml:"timeout"`
+	Optional bool       `yaml:"optional"` // never abort the run on failure
+	MetaData MetaData   `yaml:"metadata"`
+
+	// ApprovalRules decide when a page is done, all reviewers must approve if empty
+	ApprovalRules []ApprovalRule `yaml:"approval_rules"`
+	// Assignment picks reviewers for pages without reviewers
+	Assignment *AssignmentConfig `yaml:"assignment"`
+	// Reminder posts reminder comments to pending pages, requires state
+	Reminder *ReminderConfig `yaml:"reminder"`
+
+	// Groups is set from the top-level groups by the runner, to expand group names in metadata
+	Groups Groups `yaml:"-"`
 }
 
 func NewConfig(filepath string) (*Config, error) {
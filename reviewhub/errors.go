@@ -0,0 +1,99 @@
+package reviewhub
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kinds of APIError to check with errors.Is
+var (
+	ErrUnauthorized = errors.New("authentication failed")
+	ErrForbidden    = errors.New("permission denied")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrGraphQL      = errors.New("graphql error")
+)
+
+// APIError is an error response from APIs of sources
+type APIError struct {
+	// Service name like "Notion"
+	Service    string
+	StatusCode int
+	// Code is the error code or type in response body if any
+	Code    string
+	Message string
+	// Kind is one of ErrXxx, or nil for other errors
+	Kind error
+	// Hint is an actionable message for users, set by retrievers
+	Hint string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s API error", e.Service)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (HTTP %d)", e.StatusCode)
+	}
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	return msg + ": " + e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// KindOfStatus returns the kind of APIError for HTTP status code
+func KindOfStatus(code int) error {
+	switch code {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// AnnotateAPIError sets a hint to APIError in err by its kind, where tokenEnv is the env name holding
+// the api token and resource describes what is accessed like "database xxx"
+func AnnotateAPIError(err error, tokenEnv, resource string) error {
+	var e *APIError
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	switch {
+	case errors.Is(e.Kind, ErrUnauthorized):
+		e.Hint = fmt.Sprintf("token in %s is invalid or expired", tokenEnv)
+	case errors.Is(e.Kind, ErrForbidden), errors.Is(e.Kind, ErrNotFound):
+		e.Hint = fmt.Sprintf("token in %s lacks access to %s, or it doesn't exist", tokenEnv, resource)
+	case errors.Is(e.Kind, ErrRateLimited):
+		e.Hint = fmt.Sprintf("rate limit exceeded for token in %s, retry later or reduce retrievers", tokenEnv)
+	}
+	return err
+}
+
+// APIErrorHints collects hints of APIErrors in err, including joined errors
+func APIErrorHints(err error) []string {
+	var hints []string
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *APIError:
+		if e.Hint != "" {
+			hints = append(hints, fmt.Sprintf("%s (%s)", e.Hint, e.Service))
+		}
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			hints = append(hints, APIErrorHints(err)...)
+		}
+	case interface{ Unwrap() error }:
+		hints = append(hints, APIErrorHints(e.Unwrap())...)
+	}
+	return hints
+}
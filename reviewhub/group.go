@@ -0,0 +1,46 @@
+package reviewhub
+
+import "fmt"
+
+// Groups maps group names to member names, which may be other groups
+type Groups map[string][]string
+
+// Expand resolves group names in names into user names recursively, keeping other names as is
+func (g Groups) Expand(names []string) []string {
+	var expanded []string
+	seen := map[string]bool{}
+
+	var expand func(names []string)
+	expand = func(names []string) {
+		for _, n := range names {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+
+			if members, ok := g[n]; ok {
+				expand(members)
+				continue
+			}
+			expanded = append(expanded, n)
+		}
+	}
+	expand(names)
+
+	return expanded
+}
+
+// Validate checks that groups don't shadow users and have only known members
+func (g Groups) Validate(users []User) error {
+	for name, members := range g {
+		if Contains(users, User{Name: name}) {
+			return fmt.Errorf("Group %s has the same name as a user", name)
+		}
+		for _, m := range members {
+			if _, ok := g[m]; !ok && !Contains(users, User{Name: m}) {
+				return fmt.Errorf("Group %s has unknown member %s", name, m)
+			}
+		}
+	}
+	return nil
+}
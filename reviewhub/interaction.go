@@ -0,0 +1,33 @@
+package reviewhub
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Approver is implemented by retrievers which can write approvals back to the source
+type Approver interface {
+	Approve(ctx context.Context, config RetrieverConfig, page ReviewPage, user User) error
+}
+
+// Actions are performed by users on notifications, implemented by the runner
+type Actions interface {
+	// MarkReviewed approves the page of the list in the source by the user
+	MarkReviewed(ctx context.Context, list string, page Page, user User) error
+	// Snooze hides the page from notifications to the user until the time
+	Snooze(ctx context.Context, page Page, user User, until time.Time) error
+}
+
+// InteractiveNotifier is implemented by notifiers handling actions of users on notifications over HTTP
+type InteractiveNotifier interface {
+	// Handler returns nil if interactivity is disabled by the config
+	Handler(config NotifierConfig, users []User, actions Actions) (http.Handler, error)
+}
+
+// WaitingHandler is implemented by handlers performing actions after responding,
+// waited on shutdown after the server stopped accepting requests
+type WaitingHandler interface {
+	http.Handler
+	Wait()
+}
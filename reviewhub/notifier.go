@@ -1,5 +1,80 @@
 package reviewhub
 
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
 type Notifier interface {
-	Notify(NotifierConfig, User, []ReviewList) error
+	Notify(context.Context, NotifierConfig, User, []ReviewList) error
+}
+
+// Notification is review lists filtered for the user
+type Notification struct {
+	User        User
+	ReviewLists []ReviewList
+}
+
+// BatchNotifier is implemented by notifiers which see all users at once, like a channel summary.
+// The runner prefers NotifyAll to Notify per user if Batch reports true for the config.
+// Batches are outputs shared by users, so they always have all targeted users regardless of working hours.
+type BatchNotifier interface {
+	Batch(NotifierConfig) bool
+	NotifyAll(context.Context, NotifierConfig, []Notification) error
+}
+
+// NotifyErrors is returned by NotifyAll failing for some users only, keyed by user names.
+// Users missing in it are notified, while any other error fails all of them.
+type NotifyErrors map[string]error
+
+func (e NotifyErrors) Error() string {
+	return errors.Join(e.Unwrap()...).Error()
+}
+
+// Unwrap returns errors sorted by user names
+func (e NotifyErrors) Unwrap() []error {
+	var names []string
+	for n := range e {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, n := range names {
+		errs = append(errs, e[n])
+	}
+	return errs
+}
+
+// ChannelNotifier is implemented by notifiers which can post to channels, used for SLA escalations
+type ChannelNotifier interface {
+	NotifyChannel(ctx context.Context, config NotifierConfig, channel string, ls []ReviewList) error
+}
+
+// Runtime is what the runner knows beyond the config of a notifier
+type Runtime struct {
+	// Users is all known users
+	Users []User
+	// ApprovableLists is names of retrievers implementing Approver, for interactive notifiers
+	ApprovableLists []string
+	// Location is the top-level timezone, for notifiers telling days
+	Location *time.Location
+}
+
+type runtimeKey struct{}
+
+// WithRuntime passes the runtime to the notifier called with the returned context
+func WithRuntime(ctx context.Context, rt Runtime) context.Context {
+	return context.WithValue(ctx, runtimeKey{}, rt)
+}
+
+// RuntimeFrom returns the runtime of the notifier, which has no users and local time if not passed
+func RuntimeFrom(ctx context.Context) Runtime {
+	rt, _ := ctx.Value(runtimeKey{}).(Runtime)
+	if rt.Location == nil {
+		rt.Location = time.Local
+	}
+	return rt
 }
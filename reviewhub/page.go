@@ -1,9 +1,26 @@
 package reviewhub
 
+import "time"
+
 type Page struct {
+	// ID is the identifier in the source if any, used to write back to it
+	ID    string
 	Title string
 	Url   string
 	Owner User
+
+	// Zero if the source doesn't provide it
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	RequestedAt time.Time
+}
+
+// Since returns when the review has been waiting from
+func (p Page) Since() time.Time {
+	if !p.RequestedAt.IsZero() {
+		return p.RequestedAt
+	}
+	return p.CreatedAt
 }
 
 type ReviewPage struct {
@@ -11,6 +28,17 @@ type ReviewPage struct {
 
 	ApprovedReviewers []User
 	Reviewers         []User
+
+	// IsNew is set when the page has not been notified to the user before
+	IsNew bool
+
+	SLAStatus   SLAStatus
+	Escalations []User
+	// EscalationChannel is set to the channel of SLA escalations when escalated
+	EscalationChannel string
+
+	// Done is set when the page satisfies approval rules, so nobody needs to be reminded
+	Done bool
 }
 
 func NewReviewPage(title, url string, owner User, approved []User, reviewers []User) ReviewPage {
@@ -28,30 +56,37 @@ func NewReviewPage(title, url string, owner User, approved []User, reviewers []U
 type ReviewList struct {
 	Name  string
 	Pages []ReviewPage
+	// Error is set when the retriever failed, so Pages may be incomplete
+	Error string
+}
+
+func (l ReviewList) Failed() bool {
+	return l.Error != ""
 }
 
-func FilterReviewList(ls []ReviewList, reviewer User, includeApproved bool) []ReviewList {
+// FilterReviewList keeps pages the reviewer still needs to review or is escalated to
+func FilterReviewList(ls []ReviewList, reviewer User) []ReviewList {
 	var filtered []ReviewList
 	for _, l := range ls {
 		pages := []ReviewPage{}
 		for _, page := range l.Pages {
-			if !Contains(page.Reviewers, reviewer) {
+			if page.Done {
+				continue
+			}
+
+			// escalated pages are also sent to escalation users
+			if !Contains(page.Reviewers, reviewer) && !Contains(page.Escalations, reviewer) {
 				continue
 			}
 
-			if includeApproved || Contains(page.ApprovedReviewers, reviewer) {
+			if Contains(page.ApprovedReviewers, reviewer) {
 				continue
 			}
 			pages = append(pages, page)
 		}
 
-		filtered = append(
-			filtered,
-			ReviewList{
-				Name:  l.Name,
-				Pages: pages,
-			},
-		)
+		l.Pages = pages
+		filtered = append(filtered, l)
 	}
 	return filtered
 }
@@ -0,0 +1,44 @@
+package reviewhub
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterReviewList(t *testing.T) {
+	alice, bob, lead := User{Name: "alice"}, User{Name: "bob"}, User{Name: "lead"}
+	ls := []ReviewList{
+		{Name: "prs", Pages: []ReviewPage{
+			{Page: Page{Url: "pending"}, Reviewers: []User{alice, bob}},
+			{Page: Page{Url: "approved-by-alice"}, Reviewers: []User{alice, bob}, ApprovedReviewers: []User{alice}},
+			{Page: Page{Url: "done"}, Reviewers: []User{alice}, Done: true},
+			{Page: Page{Url: "escalated"}, Reviewers: []User{bob}, Escalations: []User{lead}},
+		}},
+		{Name: "failed", Error: "failed"},
+	}
+
+	tests := []struct {
+		user User
+		want []string
+	}{
+		{alice, []string{"pending"}},
+		{bob, []string{"pending", "approved-by-alice", "escalated"}},
+		{lead, []string{"escalated"}},
+	}
+	for _, tt := range tests {
+		filtered := FilterReviewList(ls, tt.user)
+		if len(filtered) != len(ls) {
+			t.Errorf("%s got %d lists, want all lists kept for failures", tt.user.Name, len(filtered))
+		}
+
+		var got []string
+		for _, l := range filtered {
+			for _, p := range l.Pages {
+				got = append(got, p.Url)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FilterReviewList(%s) = %v, want %v", tt.user.Name, got, tt.want)
+		}
+	}
+}
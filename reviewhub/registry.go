@@ -0,0 +1,99 @@
+package reviewhub
+
+import (
+	"fmt"
+	"sync"
+)
+
+type RetrieverFactory func() Retriever
+
+type NotifierFactory func() Notifier
+
+type StateStoreFactory func() StateStore
+
+var (
+	registryMu sync.RWMutex
+	retrievers = map[string]RetrieverFactory{}
+	notifiers  = map[string]NotifierFactory{}
+	stores     = map[string]StateStoreFactory{}
+)
+
+var ErrUnknownType error = fmt.Errorf("This type is not registered")
+
+// RegisterRetriever makes a retriever available by the type name in config.
+// It panics if the type is empty or already registered.
+func RegisterRetriever(typ string, factory RetrieverFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if typ == "" || factory == nil {
+		panic("reviewhub: RegisterRetriever type or factory is empty")
+	}
+	if _, dup := retrievers[typ]; dup {
+		panic("reviewhub: RegisterRetriever called twice for type " + typ)
+	}
+	retrievers[typ] = factory
+}
+
+// RegisterNotifier makes a notifier available by the type name in config.
+// It panics if the type is empty or already registered.
+func RegisterNotifier(typ string, factory NotifierFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if typ == "" || factory == nil {
+		panic("reviewhub: RegisterNotifier type or factory is empty")
+	}
+	if _, dup := notifiers[typ]; dup {
+		panic("reviewhub: RegisterNotifier called twice for type " + typ)
+	}
+	notifiers[typ] = factory
+}
+
+// RegisterStateStore makes a state store available by the type name in config.
+// It panics if the type is empty or already registered.
+func RegisterStateStore(typ string, factory StateStoreFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if typ == "" || factory == nil {
+		panic("reviewhub: RegisterStateStore type or factory is empty")
+	}
+	if _, dup := stores[typ]; dup {
+		panic("reviewhub: RegisterStateStore called twice for type " + typ)
+	}
+	stores[typ] = factory
+}
+
+func NewRetriever(typ string) (Retriever, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	f, ok := retrievers[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: retriever %q", ErrUnknownType, typ)
+	}
+	return f(), nil
+}
+
+func NewNotifier(typ string) (Notifier, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	f, ok := notifiers[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: notifier %q", ErrUnknownType, typ)
+	}
+	return f(), nil
+}
+
+func NewStateStore(typ string) (StateStore, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	f, ok := stores[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: state store %q", ErrUnknownType, typ)
+	}
+	return f(), nil
+}
@@ -0,0 +1,53 @@
+package reviewhub
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultReminderInterval = Duration(24 * time.Hour)
+	DefaultReminderMessage  = "Reminder: this is waiting for your review"
+)
+
+// ReminderConfig posts reminder comments to pages in the source by retrievers implementing Commenter
+type ReminderConfig struct {
+	// After is the age of pages to start reminding, see Page.Since
+	After Duration `yaml:"after"`
+	// Interval between reminders of a page, DefaultReminderInterval if zero
+	Interval Duration `yaml:"interval"`
+	// Message is followed by mentions of pending reviewers, DefaultReminderMessage if empty
+	Message string `yaml:"message"`
+}
+
+// Commenter is implemented by retrievers which can post comments to pages in the source
+type Commenter interface {
+	Comment(ctx context.Context, config RetrieverConfig, page ReviewPage, message string, mentions []User) error
+}
+
+// PendingReviewers returns reviewers who have not approved the page yet
+func (p ReviewPage) PendingReviewers() []User {
+	var pending []User
+	for _, u := range p.Reviewers {
+		if !Contains(p.ApprovedReviewers, u) {
+			pending = append(pending, u)
+		}
+	}
+	return pending
+}
+
+// Due reports whether the page should be reminded now, given the last reminder time or zero
+func (c ReminderConfig) Due(page ReviewPage, last, now time.Time) bool {
+	if page.Done || len(page.PendingReviewers()) == 0 {
+		return false
+	}
+	if since := page.Since(); !since.IsZero() && now.Sub(since) < time.Duration(c.After) {
+		return false
+	}
+
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultReminderInterval
+	}
+	return last.IsZero() || now.Sub(last) >= time.Duration(interval)
+}
@@ -1,5 +1,7 @@
 package reviewhub
 
+import "context"
+
 type Retriever interface {
-	Retrieve(RetrieverConfig, []User) (*ReviewList, error)
+	Retrieve(context.Context, RetrieverConfig, []User) (*ReviewList, error)
 }
@@ -0,0 +1,114 @@
+package reviewhub
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SLAStatus int
+
+const (
+	SLAOk SLAStatus = iota
+	SLAWarning
+	SLAEscalated
+)
+
+type SLAConfig struct {
+	WarnAfter     Duration `yaml:"warn_after"`
+	EscalateAfter Duration `yaml:"escalate_after"`
+	// EscalateTo is user or group names to notify pages past escalate_after, like team leads
+	EscalateTo []string `yaml:"escalate_to"`
+	// EscalateChannel is a channel to post pages past escalate_after by notifiers supporting channels, like a Slack channel
+	EscalateChannel string `yaml:"escalate_channel"`
+}
+
+// Duration is time.Duration accepting day unit like "2d" in yaml
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid duration: %s", s)
+		}
+		*d = Duration(time.Duration(n * float64(24*time.Hour)))
+		return nil
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ApplySLA sets SLAStatus to pages by their age, and adds escalation users to pages past escalate_after
+func ApplySLA(l *ReviewList, sla *SLAConfig, groups Groups, knownUsers []User, now time.Time) {
+	if sla == nil {
+		return
+	}
+
+	var escalateTo []User
+	for _, u := range knownUsers {
+		for _, n := range groups.Expand(sla.EscalateTo) {
+			if u.Name == n {
+				escalateTo = append(escalateTo, u)
+			}
+		}
+	}
+
+	for i, page := range l.Pages {
+		since := page.Since()
+		if page.Done || since.IsZero() {
+			// retriever doesn't know when the page is created
+			continue
+		}
+		age := now.Sub(since)
+
+		switch {
+		case sla.EscalateAfter > 0 && age >= time.Duration(sla.EscalateAfter):
+			l.Pages[i].SLAStatus = SLAEscalated
+			l.Pages[i].Escalations = escalateTo
+			l.Pages[i].EscalationChannel = sla.EscalateChannel
+		case sla.WarnAfter > 0 && age >= time.Duration(sla.WarnAfter):
+			l.Pages[i].SLAStatus = SLAWarning
+		}
+	}
+}
+
+// FormatAge formats duration roughly like "3d", "5h" or "10m"
+func FormatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
+
+// FilterEscalations returns lists of pages escalated to the channel
+func FilterEscalations(ls []ReviewList, channel string) []ReviewList {
+	var filtered []ReviewList
+	for _, l := range ls {
+		pages := []ReviewPage{}
+		for _, page := range l.Pages {
+			if !page.Done && page.SLAStatus == SLAEscalated && page.EscalationChannel == channel {
+				pages = append(pages, page)
+			}
+		}
+		if len(pages) > 0 {
+			l.Pages = pages
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,161 @@
+package reviewhub
+
+import (
+	"context"
+	"time"
+)
+
+// State is persisted across runs by StateStore
+type State struct {
+	// Notified records when each page was first notified: notifier name -> user name -> page url -> time
+	Notified map[string]map[string]map[string]time.Time `json:"notified"`
+	// Assignments records reviewers assigned by the runner: retriever name -> page url -> user names
+	Assignments map[string]map[string][]string `json:"assignments"`
+	// RoundRobin is the cursor of round robin assignment: retriever name -> index
+	RoundRobin map[string]int `json:"round_robin"`
+	// Reminded records when each page was reminded last: retriever name -> page url -> time
+	Reminded map[string]map[string]time.Time `json:"reminded"`
+	// Snoozed records pages hidden from users: user name -> page url -> until
+	Snoozed map[string]map[string]time.Time `json:"snoozed"`
+	// Notifiers is data kept by notifiers themselves: notifier name -> key -> value
+	Notifiers map[string]NotifierState `json:"notifiers"`
+}
+
+// NotifierState is a key value store of a notifier persisted across runs
+type NotifierState map[string]string
+
+type StateConfig struct {
+	Type     string   `yaml:"type"`
+	MetaData MetaData `yaml:"metadata"`
+}
+
+type StateStore interface {
+	Load(StateConfig) (*State, error)
+	Save(StateConfig, *State) error
+}
+
+func NewState() *State {
+	return &State{
+		Notified:    map[string]map[string]map[string]time.Time{},
+		Assignments: map[string]map[string][]string{},
+		RoundRobin:  map[string]int{},
+		Reminded:    map[string]map[string]time.Time{},
+		Snoozed:     map[string]map[string]time.Time{},
+		Notifiers:   map[string]NotifierState{},
+	}
+}
+
+// NotifierState returns the store of the notifier, which is saved with the state
+func (s *State) NotifierState(notifier string) NotifierState {
+	if s.Notifiers == nil {
+		s.Notifiers = map[string]NotifierState{}
+	}
+	if s.Notifiers[notifier] == nil {
+		s.Notifiers[notifier] = NotifierState{}
+	}
+	return s.Notifiers[notifier]
+}
+
+type notifierStateKey struct{}
+
+// WithNotifierState passes the store to the notifier called with the returned context
+func WithNotifierState(ctx context.Context, s NotifierState) context.Context {
+	return context.WithValue(ctx, notifierStateKey{}, s)
+}
+
+// NotifierStateFrom returns the store of the notifier, or false without state
+func NotifierStateFrom(ctx context.Context) (NotifierState, bool) {
+	s, ok := ctx.Value(notifierStateKey{}).(NotifierState)
+	return s, ok
+}
+
+// MarkNew sets IsNew to pages which are not notified to the user by the notifier yet
+func (s *State) MarkNew(notifier string, user User, ls []ReviewList) []ReviewList {
+	notified := s.Notified[notifier][user.Name]
+
+	var marked []ReviewList
+	for _, l := range ls {
+		pages := []ReviewPage{}
+		for _, page := range l.Pages {
+			_, ok := notified[page.Url]
+			page.IsNew = !ok
+			pages = append(pages, page)
+		}
+		l.Pages = pages
+		marked = append(marked, l)
+	}
+	return marked
+}
+
+// Record stores pages notified to the user, and forgets pages no longer in the lists
+func (s *State) Record(notifier string, user User, ls []ReviewList, now time.Time) {
+	if s.Notified == nil {
+		s.Notified = map[string]map[string]map[string]time.Time{}
+	}
+	if s.Notified[notifier] == nil {
+		s.Notified[notifier] = map[string]map[string]time.Time{}
+	}
+	prev := s.Notified[notifier][user.Name]
+
+	notified := map[string]time.Time{}
+	for _, l := range ls {
+		for _, page := range l.Pages {
+			if t, ok := prev[page.Url]; ok {
+				notified[page.Url] = t
+			} else {
+				notified[page.Url] = now
+			}
+		}
+	}
+	s.Notified[notifier][user.Name] = notified
+}
+
+// Snooze hides the page from the user until the time
+func (s *State) Snooze(user User, url string, until time.Time) {
+	if s.Snoozed == nil {
+		s.Snoozed = map[string]map[string]time.Time{}
+	}
+	if s.Snoozed[user.Name] == nil {
+		s.Snoozed[user.Name] = map[string]time.Time{}
+	}
+	s.Snoozed[user.Name][url] = until
+}
+
+// FilterSnoozed drops pages snoozed by the user at now, and forgets expired snoozes of the user
+func (s *State) FilterSnoozed(user User, ls []ReviewList, now time.Time) []ReviewList {
+	snoozed := s.Snoozed[user.Name]
+	for url, until := range snoozed {
+		if !now.Before(until) {
+			delete(snoozed, url)
+		}
+	}
+
+	var filtered []ReviewList
+	for _, l := range ls {
+		pages := []ReviewPage{}
+		for _, page := range l.Pages {
+			if _, ok := snoozed[page.Url]; !ok {
+				pages = append(pages, page)
+			}
+		}
+		l.Pages = pages
+		filtered = append(filtered, l)
+	}
+	return filtered
+}
+
+// FilterNewPages drops pages which are not marked as new
+func FilterNewPages(ls []ReviewList) []ReviewList {
+	var filtered []ReviewList
+	for _, l := range ls {
+		pages := []ReviewPage{}
+		for _, page := range l.Pages {
+			if page.IsNew {
+				pages = append(pages, page)
+			}
+		}
+		l.Pages = pages
+		filtered = append(filtered, l)
+	}
+	return filtered
+}
@@ -1,9 +1,26 @@
 package reviewhub
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type User struct {
-	Name     string   `yaml:"name" validate:"required"`
-	MetaData MetaData `yaml:"metadata"`
-	Unknown  bool
+	Name         string        `yaml:"name" validate:"required"`
+	MetaData     MetaData      `yaml:"metadata"`
+	OnlyNew      bool          `yaml:"only_new"`
+	Timezone     string        `yaml:"timezone"`
+	WorkingHours *WorkingHours `yaml:"working_hours"`
+	Unknown      bool
+}
+
+type WorkingHours struct {
+	// Start and End in "15:04" format, End may be earlier than Start for night shifts
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Days like "mon", every day if empty
+	Days []string `yaml:"days"`
 }
 
 func NewUnknownUser(name string) *User {
@@ -21,3 +38,90 @@ func Contains(users []User, target User) bool {
 	}
 	return false
 }
+
+// FindUser returns the known user whose account id in user metadata T by idOf, or name, equals id.
+// Users with metadata not satisfying T are skipped, and nil is returned for empty id of deleted accounts.
+func FindUser[T any](knownUsers []User, id string, idOf func(T) string) *User {
+	if id == "" {
+		return nil
+	}
+
+	for _, u := range knownUsers {
+		umeta, err := ParseMetaData[T](u.MetaData)
+		if err != nil {
+			continue // skip this user
+		}
+
+		if id == idOf(*umeta) || id == u.Name {
+			return &u
+		}
+	}
+	return nil
+}
+
+// Location returns the timezone of user, local time if not set
+func (u User) Location() (*time.Location, error) {
+	if u.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(u.Timezone)
+}
+
+// InWorkingHours reports whether t is in the working hours of user in the timezone of user.
+// Users without working_hours are always in working hours.
+func (u User) InWorkingHours(t time.Time) (bool, error) {
+	if u.WorkingHours == nil {
+		return true, nil
+	}
+
+	loc, err := u.Location()
+	if err != nil {
+		return false, err
+	}
+	return u.WorkingHours.contains(t.In(loc))
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func (w WorkingHours) contains(t time.Time) (bool, error) {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false, fmt.Errorf("Invalid working_hours start: %w", err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return false, fmt.Errorf("Invalid working_hours end: %w", err)
+	}
+
+	if len(w.Days) > 0 {
+		found := false
+		for _, d := range w.Days {
+			wd, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				return false, fmt.Errorf("Invalid working_hours day: %s", d)
+			}
+			if wd == t.Weekday() {
+				found = true
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	minutes := t.Hour()*60 + t.Minute()
+	s := start.Hour()*60 + start.Minute()
+	e := end.Hour()*60 + end.Minute()
+	if s <= e {
+		return s <= minutes && minutes < e, nil
+	}
+	return minutes >= s || minutes < e, nil
+}
@@ -0,0 +1,39 @@
+package reviewhub_test
+
+import (
+	"testing"
+
+	"github.com/ry023/reviewhub/retrievers/ghdiscussions"
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// FindUser is tested with metadata of a real retriever, whose github_id is optional
+func TestFindUser(t *testing.T) {
+	users := []reviewhub.User{
+		{Name: "alice", MetaData: map[any]any{"github_id": "alice-gh"}},
+		{Name: "bob", MetaData: map[any]any{"github_id": "bob-gh"}},
+		// no github_id, so found only by name
+		{Name: "carol"},
+		{Name: "dave", MetaData: map[any]any{"github_id": 123}},
+	}
+	idOf := func(m ghdiscussions.UserMetaData) string { return m.GitHubId }
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"alice-gh", "alice"},
+		{"bob", "bob"},
+		{"carol", "carol"},
+		{"123", "dave"},
+		{"erin", ""},
+		// deleted accounts never match users without github_id
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := reviewhub.FindUser(users, tt.id, idOf)
+		if (got == nil && tt.want != "") || (got != nil && got.Name != tt.want) {
+			t.Errorf("FindUser(%q) = %v, want %q", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package runners
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// MarkReviewed approves the page of the list in the source by the user
+func (r *ReviewHubRunner) MarkReviewed(ctx context.Context, list string, page reviewhub.Page, user reviewhub.User) error {
+	for _, v := range r.retrievers {
+		if v.config.Name != list {
+			continue
+		}
+
+		approver, ok := v.retriever.(reviewhub.Approver)
+		if !ok {
+			return fmt.Errorf("Retriever type %s does not support marking reviewed", v.config.Type)
+		}
+		return approver.Approve(ctx, v.config, reviewhub.ReviewPage{Page: page}, user)
+	}
+	return fmt.Errorf("Retriever %s not found", list)
+}
+
+// Snooze hides the page from notifications to the user until the time, which requires state
+func (r *ReviewHubRunner) Snooze(ctx context.Context, page reviewhub.Page, user reviewhub.User, until time.Time) error {
+	if r.store == nil {
+		return fmt.Errorf("Snooze requires state")
+	}
+
+	// not to be overwritten by runs
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.store.Load(*r.config.State)
+	if err != nil {
+		return fmt.Errorf("Failed to load state: %w", err)
+	}
+	state.Snooze(user, page.Url, until)
+	if err := r.store.Save(*r.config.State, state); err != nil {
+		return fmt.Errorf("Failed to save state: %w", err)
+	}
+	return nil
+}
+
+// Handlers returns HTTP handlers of interactive notifiers keyed by notifier name
+func (r *ReviewHubRunner) Handlers() (map[string]http.Handler, error) {
+	handlers := map[string]http.Handler{}
+	for _, v := range r.notifiers {
+		in, ok := v.notifier.(reviewhub.InteractiveNotifier)
+		if !ok {
+			continue
+		}
+
+		h, err := in.Handler(v.config, r.users, r)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create handler of %s: %w", v.config.Name, err)
+		}
+		if h != nil {
+			handlers[v.config.Name] = h
+		}
+	}
+	return handlers, nil
+}
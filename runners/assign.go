@@ -0,0 +1,162 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// assign sets reviewers to pages without reviewers by assignment configs of retrievers.
+// Assignments are kept in state so pages keep their reviewers across runs.
+// Failures of write backs never abort the run, as failed pages are retried on next runs.
+func (r *ReviewHubRunner) assign(ctx context.Context, ls []reviewhub.ReviewList, state *reviewhub.State) error {
+	if state == nil {
+		// assignments are not kept across runs without state store
+		state = reviewhub.NewState()
+	}
+	if state.Assignments == nil {
+		state.Assignments = map[string]map[string][]string{}
+	}
+	if state.RoundRobin == nil {
+		state.RoundRobin = map[string]int{}
+	}
+
+	// pages done by approval rules are not loads, so rules must be applied before
+	load := reviewhub.Outstanding(ls)
+
+	var errs []error
+	for i, v := range r.retrievers {
+		if v.config.Assignment == nil || ls[i].Failed() {
+			continue
+		}
+
+		if err := r.assignList(ctx, v, &ls[i], state, load); err != nil {
+			err = fmt.Errorf("Failed to assign reviewers of %s: %w", v.config.Name, err)
+			log.Print(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *ReviewHubRunner) assignList(ctx context.Context, v retriever, l *reviewhub.ReviewList, state *reviewhub.State, load map[string]int) error {
+	a := *v.config.Assignment
+
+	var candidates []reviewhub.User
+	for _, n := range v.config.Groups.Expand(a.From) {
+		for _, u := range r.users {
+			if u.Name == n {
+				candidates = append(candidates, u)
+			}
+		}
+	}
+
+	var assigner reviewhub.ReviewerAssigner
+	if a.WriteBack {
+		// checked by validateAssignment
+		assigner = v.retriever.(reviewhub.ReviewerAssigner)
+	}
+
+	prev := state.Assignments[v.config.Name]
+	assigned := map[string][]string{}
+	cursor := state.RoundRobin[v.config.Name]
+
+	var errs []error
+	for i, page := range l.Pages {
+		if len(page.Reviewers) > 0 {
+			continue
+		}
+
+		var reviewers []reviewhub.User
+		if names, ok := prev[page.Url]; ok {
+			// keep reviewers assigned before
+			for _, u := range r.users {
+				for _, n := range names {
+					if u.Name == n {
+						reviewers = append(reviewers, u)
+					}
+				}
+			}
+		} else {
+			reviewers = reviewhub.PickReviewers(a, candidates, page.Owner, load, &cursor)
+			log.Printf("Assign %d reviewers to %s in %s", len(reviewers), page.Url, v.config.Name)
+		}
+		if len(reviewers) == 0 {
+			continue
+		}
+
+		// pages keep no reviewers in the source until written back successfully, so retried on next runs
+		if assigner != nil {
+			err := assigner.AssignReviewers(ctx, v.config, page, reviewers)
+			if errors.Is(err, reviewhub.ErrAlreadyAssigned) {
+				// reviewers are unknown users, who are left as is
+				log.Printf("Skip assigning to %s in %s: %v", page.Url, v.config.Name, err)
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Failed to write back reviewers of %s: %w", page.Url, err))
+				if r.failFast() {
+					// the source is likely down, so the rest are written back on next runs
+					assigner = nil
+				}
+			}
+		}
+
+		var names []string
+		for _, u := range reviewers {
+			names = append(names, u.Name)
+		}
+		assigned[page.Url] = names
+		l.Pages[i].Reviewers = reviewers
+	}
+
+	// forget pages no longer unassigned
+	state.Assignments[v.config.Name] = assigned
+	state.RoundRobin[v.config.Name] = cursor
+
+	return errors.Join(errs...)
+}
+
+func validateAssignment(config reviewhub.RetrieverConfig, r reviewhub.Retriever, state *reviewhub.StateConfig, users []reviewhub.User) error {
+	a := config.Assignment
+	if a == nil {
+		return nil
+	}
+
+	if len(a.From) == 0 {
+		return fmt.Errorf("Assignment of %s requires 'from'", config.Name)
+	}
+	switch a.Strategy {
+	case "", reviewhub.AssignmentRoundRobin, reviewhub.AssignmentLeastLoaded:
+	default:
+		return fmt.Errorf("Invalid assignment strategy of %s: %s", config.Name, a.Strategy)
+	}
+	if _, ok := r.(reviewhub.ReviewerAssigner); a.WriteBack && !ok {
+		return fmt.Errorf("Retriever type %s does not support assignment write_back", config.Type)
+	}
+	if rv, ok := r.(reviewhub.ReviewerValidator); a.WriteBack && ok {
+		var candidates []reviewhub.User
+		for _, u := range users {
+			if slices.Contains(config.Groups.Expand(a.From), u.Name) {
+				candidates = append(candidates, u)
+			}
+		}
+		if err := rv.ValidateReviewers(candidates); err != nil {
+			return fmt.Errorf("Assignment of %s can not write back: %w", config.Name, err)
+		}
+	}
+	if state == nil {
+		// the cursor and assignments not written back live only in state, so would be picked again every run
+		if a.Strategy == reviewhub.AssignmentRoundRobin {
+			return fmt.Errorf("Assignment of %s requires state for round_robin", config.Name)
+		}
+		if !a.WriteBack {
+			return fmt.Errorf("Assignment of %s requires state or write_back to keep reviewers", config.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestAssignRoundRobin(t *testing.T) {
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{
+		page("p1", "erin", nil),
+		page("p2", "alice", nil),
+		page("p3", "erin", nil),
+		page("reviewed", "erin", users("bob")),
+	}}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{
+			Name: "prs",
+			Assignment: &reviewhub.AssignmentConfig{
+				From:      []string{"team"},
+				Strategy:  reviewhub.AssignmentRoundRobin,
+				WriteBack: true,
+			},
+		}},
+		Users:  users("alice", "bob", "carol", "erin"),
+		Groups: reviewhub.Groups{"team": {"alice", "bob", "carol"}},
+		State:  fileState(t),
+	}, []*fakeRetriever{f}, nil)
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	// owners are skipped, and pages with reviewers are kept as is
+	want := map[string][]string{"p1": {"alice"}, "p2": {"bob"}, "p3": {"carol"}}
+	for url, w := range want {
+		if !slices.Equal(f.assigned[url], w) {
+			t.Errorf("first run wrote back %v to %s, want %v", f.assigned[url], url, w)
+		}
+	}
+	if _, ok := f.assigned["reviewed"]; ok {
+		t.Error("Page with reviewers is assigned")
+	}
+
+	// assignments are kept across runs while the cursor goes on for new pages
+	f.pages = append([]reviewhub.ReviewPage{page("p4", "erin", nil)}, f.pages...)
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	want["p4"] = []string{"alice"}
+	for url, w := range want {
+		if !slices.Equal(f.assigned[url], w) {
+			t.Errorf("second run wrote back %v to %s, want %v", f.assigned[url], url, w)
+		}
+	}
+
+	state, err := r.store.Load(*r.config.State)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := len(state.Assignments["prs"]); got != 4 {
+		t.Errorf("state has %d assignments, want 4", got)
+	}
+	if got := state.RoundRobin["prs"]; got != 1 {
+		t.Errorf("round robin cursor = %d, want 1", got)
+	}
+}
+
+func TestAssignLeastLoaded(t *testing.T) {
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{
+		page("x1", "erin", users("alice")),
+		page("x2", "erin", users("alice")),
+		page("x3", "erin", users("bob")),
+		// done pages are not loads
+		page("done", "erin", users("carol"), "carol"),
+		page("u1", "erin", nil),
+		page("u2", "erin", nil),
+	}}
+	n := &fakeNotifier{}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{
+			Name:       "prs",
+			Assignment: &reviewhub.AssignmentConfig{From: []string{"alice", "bob", "carol"}},
+		}},
+		Notifiers: []reviewhub.NotifierConfig{{Name: "n"}},
+		Users:     users("alice", "bob", "carol"),
+		State:     fileState(t),
+	}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// ties are broken by config order
+	want := map[string][]string{
+		"alice": {"x1*", "x2*"},
+		"bob":   {"x3*", "u2*"},
+		"carol": {"u1*"},
+	}
+	for u, w := range want {
+		if !slices.Equal(n.got[u], w) {
+			t.Errorf("%s notified of %v, want %v", u, n.got[u], w)
+		}
+	}
+	if len(f.assigned) != 0 {
+		t.Errorf("wrote back %v without write_back", f.assigned)
+	}
+}
+
+func TestAssignWriteBackFailure(t *testing.T) {
+	failing := errors.New("write back failed")
+	tests := []struct {
+		policy      string
+		wantWritten []string // page urls written back
+	}{
+		// the rest are written back on next runs
+		{reviewhub.FailurePolicyFailFast, nil},
+		{reviewhub.FailurePolicyContinue, []string{"p3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			f := &fakeRetriever{
+				pages: []reviewhub.ReviewPage{page("p1", "erin", nil), page("p2", "erin", nil), page("p3", "erin", nil)},
+				assignErr: map[string]error{
+					"p1": reviewhub.ErrAlreadyAssigned,
+					"p2": failing,
+				},
+			}
+			n := &fakeNotifier{}
+			r := newTestRunner(t, reviewhub.Config{
+				Retrievers: []reviewhub.RetrieverConfig{{
+					Name: "prs",
+					Assignment: &reviewhub.AssignmentConfig{
+						From:      []string{"alice", "bob", "carol"},
+						Strategy:  reviewhub.AssignmentRoundRobin,
+						WriteBack: true,
+					},
+				}},
+				Notifiers:     []reviewhub.NotifierConfig{{Name: "n"}},
+				Users:         users("alice", "bob", "carol"),
+				State:         fileState(t),
+				FailurePolicy: tt.policy,
+			}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+			if err := r.Run(context.Background()); !errors.Is(err, failing) {
+				t.Errorf("Run() error = %v, want the write back failure", err)
+			}
+
+			var written []string
+			for url := range f.assigned {
+				written = append(written, url)
+			}
+			if !slices.Equal(written, tt.wantWritten) {
+				t.Errorf("written back %v, want %v", written, tt.wantWritten)
+			}
+
+			// pages already having reviewers in the source are left as is
+			want := map[string][]string{"alice": nil, "bob": {"p2*"}, "carol": {"p3*"}}
+			for u, w := range want {
+				if !slices.Equal(n.got[u], w) {
+					t.Errorf("%s notified of %v, want %v", u, n.got[u], w)
+				}
+			}
+
+			state, err := r.store.Load(*r.config.State)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			assigned := state.Assignments["prs"]
+			if _, ok := assigned["p1"]; ok || len(assigned) != 2 {
+				t.Errorf("state has assignments %v, want p2 and p3 to retry write backs", assigned)
+			}
+			if got := state.RoundRobin["prs"]; got != 0 {
+				t.Errorf("round robin cursor = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestValidateAssignment(t *testing.T) {
+	state := &reviewhub.StateConfig{Type: "file"}
+	tests := []struct {
+		name    string
+		a       reviewhub.AssignmentConfig
+		state   *reviewhub.StateConfig
+		wantErr bool
+	}{
+		{"least loaded with state", reviewhub.AssignmentConfig{From: []string{"alice"}}, state, false},
+		{"no from", reviewhub.AssignmentConfig{}, state, true},
+		{"unknown strategy", reviewhub.AssignmentConfig{From: []string{"alice"}, Strategy: "random"}, state, true},
+		{"round robin without state", reviewhub.AssignmentConfig{From: []string{"alice"}, Strategy: reviewhub.AssignmentRoundRobin, WriteBack: true}, nil, true},
+		{"write back without state", reviewhub.AssignmentConfig{From: []string{"alice"}, WriteBack: true}, nil, false},
+		{"nowhere to keep reviewers", reviewhub.AssignmentConfig{From: []string{"alice"}}, nil, true},
+		{"write back of a user without account", reviewhub.AssignmentConfig{From: []string{"alice", "ghost"}, WriteBack: true}, state, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := reviewhub.RetrieverConfig{Name: "prs", Type: "fake", Assignment: &tt.a}
+			err := validateAssignment(config, new(fakeRetriever), tt.state, users("alice", "ghost"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAssignment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package runners
+
+// Built-in retrievers and notifiers register themselves to reviewhub on import
+import (
+	_ "github.com/ry023/reviewhub/notifiers/email"
+	_ "github.com/ry023/reviewhub/notifiers/report"
+	_ "github.com/ry023/reviewhub/notifiers/slack"
+	_ "github.com/ry023/reviewhub/notifiers/stdout"
+	_ "github.com/ry023/reviewhub/plugins/exec"
+	_ "github.com/ry023/reviewhub/retrievers/ghdiscussions"
+	_ "github.com/ry023/reviewhub/retrievers/ghpullrequests"
+	_ "github.com/ry023/reviewhub/retrievers/glmergerequests"
+	_ "github.com/ry023/reviewhub/retrievers/notion"
+	_ "github.com/ry023/reviewhub/stores/file"
+)
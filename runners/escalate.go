@@ -0,0 +1,49 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// escalate posts escalated pages to their escalation channels by targeted notifiers supporting channels
+func (r *ReviewHubRunner) escalate(ctx context.Context, ls []reviewhub.ReviewList, targets map[string][]reviewhub.User) error {
+	var channels []string
+	for _, l := range ls {
+		for _, page := range l.Pages {
+			if page.EscalationChannel != "" && !slices.Contains(channels, page.EscalationChannel) {
+				channels = append(channels, page.EscalationChannel)
+			}
+		}
+	}
+
+	var errs []error
+	for _, v := range r.notifiers {
+		cn, ok := v.notifier.(reviewhub.ChannelNotifier)
+		if _, targeted := targets[v.config.Name]; !ok || !targeted {
+			continue
+		}
+
+		for _, ch := range channels {
+			escalated := reviewhub.FilterEscalations(ls, ch)
+			if len(escalated) == 0 {
+				// done pages are not escalated
+				continue
+			}
+
+			if err := cn.NotifyChannel(ctx, v.config, ch, escalated); err != nil {
+				err = fmt.Errorf("Failed to escalate to %s by %s: %w", ch, v.config.Name, err)
+				log.Print(err)
+				errs = append(errs, err)
+				if r.failFast() {
+					break
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
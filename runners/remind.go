@@ -0,0 +1,84 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// remind posts reminder comments to pending pages by reminder configs of retrievers.
+// Reminders are throttled by the last reminded time kept in state.
+func (r *ReviewHubRunner) remind(ctx context.Context, ls []reviewhub.ReviewList, state *reviewhub.State, now time.Time) error {
+	if state.Reminded == nil {
+		state.Reminded = map[string]map[string]time.Time{}
+	}
+
+	var errs []error
+	for i, v := range r.retrievers {
+		if v.config.Reminder == nil || ls[i].Failed() {
+			continue
+		}
+
+		c := *v.config.Reminder
+		message := c.Message
+		if message == "" {
+			message = reviewhub.DefaultReminderMessage
+		}
+		// checked by validateReminder
+		commenter := v.retriever.(reviewhub.Commenter)
+
+		// pages no longer in the list are forgotten
+		prev := state.Reminded[v.config.Name]
+		reminded := map[string]time.Time{}
+		for _, page := range ls[i].Pages {
+			if last, ok := prev[page.Url]; ok {
+				reminded[page.Url] = last
+			}
+		}
+
+		var failed error
+		for _, page := range ls[i].Pages {
+			if !c.Due(page, reminded[page.Url], now) {
+				continue
+			}
+
+			if err := commenter.Comment(ctx, v.config, page, message, page.PendingReviewers()); err != nil {
+				err = fmt.Errorf("Failed to remind %s in %s: %w", page.Url, v.config.Name, err)
+				if r.failFast() {
+					failed = err
+					break
+				}
+				log.Print(err)
+				errs = append(errs, err)
+				continue
+			}
+			log.Printf("Reminded %s in %s", page.Url, v.config.Name)
+			reminded[page.Url] = now
+		}
+
+		// replaced after the loop to keep pages not reached on failure
+		state.Reminded[v.config.Name] = reminded
+		if failed != nil {
+			return failed
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateReminder(config reviewhub.RetrieverConfig, r reviewhub.Retriever, state *reviewhub.StateConfig) error {
+	if config.Reminder == nil {
+		return nil
+	}
+
+	if state == nil {
+		return fmt.Errorf("Reminder of %s requires state to throttle reminders", config.Name)
+	}
+	if _, ok := r.(reviewhub.Commenter); !ok {
+		return fmt.Errorf("Retriever type %s does not support reminder", config.Type)
+	}
+	return nil
+}
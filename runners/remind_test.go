@@ -0,0 +1,44 @@
+package runners
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func TestRemind(t *testing.T) {
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{
+		page("pending", "erin", users("alice", "bob"), "bob"),
+		page("approved", "erin", users("alice"), "alice"),
+	}}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{
+			Name:     "prs",
+			Reminder: &reviewhub.ReminderConfig{Interval: reviewhub.Duration(24 * time.Hour)},
+		}},
+		Users: users("alice", "bob"),
+		State: fileState(t),
+	}, []*fakeRetriever{f}, nil)
+
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		after time.Duration
+		want  []string // page urls commented so far
+	}{
+		{0, []string{"pending"}},
+		// throttled by the interval kept in state
+		{time.Hour, []string{"pending"}},
+		{25 * time.Hour, []string{"pending", "pending"}},
+	}
+	for _, tt := range tests {
+		if err := r.run(context.Background(), nil, start.Add(tt.after), false); err != nil {
+			t.Fatalf("run() after %s error = %v", tt.after, err)
+		}
+		if !slices.Equal(f.comments, tt.want) {
+			t.Errorf("after %s commented %v, want %v", tt.after, f.comments, tt.want)
+		}
+	}
+}
@@ -1,13 +1,13 @@
 package runners
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
-	"github.com/ry023/reviewhub/notifiers/slack"
-	"github.com/ry023/reviewhub/notifiers/stdout"
-	"github.com/ry023/reviewhub/retrievers/ghdiscussions"
-	"github.com/ry023/reviewhub/retrievers/notion"
 	"github.com/ry023/reviewhub/reviewhub"
 )
 
@@ -17,6 +17,20 @@ type ReviewHubRunner struct {
 	users      []reviewhub.User
 	notifiers  []notifier
 	retrievers []retriever
+	store      reviewhub.StateStore
+	// runtime is passed to notifiers by the context
+	runtime reviewhub.Runtime
+
+	mu sync.Mutex
+	// deferred is users skipped out of working hours: notifier name -> user name -> deferral
+	deferred map[string]map[string]*deferral
+}
+
+// deferral tracks failed deferred runs of a deferred user
+type deferral struct {
+	failures int
+	// retryAt is when the next deferred run may notify the user after failures
+	retryAt time.Time
 }
 
 type notifier struct {
@@ -29,12 +43,49 @@ type retriever struct {
 	retriever reviewhub.Retriever
 }
 
-var ErrNotBuiltIn error = fmt.Errorf("This type is not built-in")
+// ErrNotBuiltIn is returned for types not registered to reviewhub, see reviewhub.ErrUnknownType
+var ErrNotBuiltIn = reviewhub.ErrUnknownType
 
 func New(config *reviewhub.Config) (*ReviewHubRunner, error) {
+	if err := config.Groups.Validate(config.Users); err != nil {
+		return nil, err
+	}
+
+	var retrievers []retriever
+	for _, c := range config.Retrievers {
+		c.Groups = config.Groups
+		r, err := newRetriever(&c)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateAssignment(c, r, config.State, config.Users); err != nil {
+			return nil, err
+		}
+		if err := validateReminder(c, r, config.State); err != nil {
+			return nil, err
+		}
+		retrievers = append(retrievers, retriever{
+			retriever: r,
+			config:    c,
+		})
+	}
+
+	// for buttons marking pages reviewed
+	var approvable []string
+	for _, v := range retrievers {
+		if _, ok := v.retriever.(reviewhub.Approver); ok {
+			approvable = append(approvable, v.config.Name)
+		}
+	}
+
+	loc, err := config.Location()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid timezone: %w", err)
+	}
+
 	var notifiers []notifier
 	for _, c := range config.Notifiers {
-		n, err := parseBuiltinNotifier(&c)
+		n, err := newNotifier(&c)
 		if err != nil {
 			return nil, err
 		}
@@ -44,16 +95,25 @@ func New(config *reviewhub.Config) (*ReviewHubRunner, error) {
 		})
 	}
 
-	var retrievers []retriever
-	for _, c := range config.Retrievers {
-		r, err := parseBuiltinRetriever(&c)
+	switch config.FailurePolicy {
+	case "", reviewhub.FailurePolicyFailFast, reviewhub.FailurePolicyContinue:
+	default:
+		return nil, fmt.Errorf("Invalid failure_policy: %s", config.FailurePolicy)
+	}
+
+	for _, u := range config.Users {
+		if _, err := u.InWorkingHours(time.Now()); err != nil {
+			return nil, fmt.Errorf("Invalid working hours of user %s: %w", u.Name, err)
+		}
+	}
+
+	var store reviewhub.StateStore
+	if config.State != nil {
+		s, err := newStateStore(config.State)
 		if err != nil {
 			return nil, err
 		}
-		retrievers = append(retrievers, retriever{
-			retriever: r,
-			config:    c,
-		})
+		store = s
 	}
 
 	return &ReviewHubRunner{
@@ -61,58 +121,344 @@ func New(config *reviewhub.Config) (*ReviewHubRunner, error) {
 		notifiers:  notifiers,
 		users:      config.Users,
 		retrievers: retrievers,
+		store:      store,
+		runtime: reviewhub.Runtime{
+			Users:           config.Users,
+			ApprovableLists: approvable,
+			Location:        loc,
+		},
+		deferred: map[string]map[string]*deferral{},
 	}, nil
 }
 
-func (r *ReviewHubRunner) Run() error {
-	var ls []reviewhub.ReviewList
-	for _, v := range r.retrievers {
-		l, err := v.retriever.Retrieve(v.config, r.users)
+const (
+	defaultConcurrency      = 4
+	defaultRetrieverTimeout = 5 * time.Minute
+
+	// deferred users are given up after failing this many times, waiting longer after each failure
+	maxDeferredFailures = 3
+	deferredBackoff     = 5 * time.Minute
+)
+
+// Run retrieves all sources and notifies by all notifiers
+func (r *ReviewHubRunner) Run(ctx context.Context) error {
+	var names []string
+	for _, v := range r.notifiers {
+		names = append(names, v.config.Name)
+	}
+	return r.RunNotifiers(ctx, names...)
+}
+
+// RunNotifiers retrieves all sources and notifies only by the named notifiers.
+// Users out of their working hours are skipped and deferred until RunDeferred.
+func (r *ReviewHubRunner) RunNotifiers(ctx context.Context, names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets := map[string][]reviewhub.User{}
+	for _, name := range names {
+		targets[name] = r.users
+	}
+	return r.run(ctx, targets, time.Now(), true)
+}
+
+// RunDeferred notifies deferred users who are in their working hours now.
+// Users failing to be notified are retried with backoff, and given up after maxDeferredFailures.
+func (r *ReviewHubRunner) RunDeferred(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runDeferred(ctx, time.Now())
+}
+
+func (r *ReviewHubRunner) runDeferred(ctx context.Context, now time.Time) error {
+	targets := map[string][]reviewhub.User{}
+	for _, u := range r.users {
+		if in, _ := u.InWorkingHours(now); !in {
+			continue
+		}
+		for name, users := range r.deferred {
+			if d, ok := users[u.Name]; ok && !now.Before(d.retryAt) {
+				targets[name] = append(targets[name], u)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// escalations are posted to channels by the scheduled run
+	err := r.run(ctx, targets, now, false)
+
+	// users notified are cleared, so the rest failed and the errors are logged by run
+	for name, users := range targets {
+		for _, u := range users {
+			d, ok := r.deferred[name][u.Name]
+			if !ok {
+				continue
+			}
+			d.failures++
+			if d.failures >= maxDeferredFailures {
+				log.Printf("Give up notifying to %s by %s after %d failures of deferred runs", u.Name, name, d.failures)
+				r.setDeferred(name, u, false)
+				continue
+			}
+			d.retryAt = now.Add(deferredBackoff << (d.failures - 1))
+		}
+	}
+	return err
+}
+
+// run retrieves all sources and notifies targets: notifier name -> users.
+// SLA escalations are also posted to channels by the targeted notifiers if escalate.
+func (r *ReviewHubRunner) run(ctx context.Context, targets map[string][]reviewhub.User, now time.Time, escalate bool) error {
+	ls, retrieveErrs, err := r.retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	var state *reviewhub.State
+	if r.store != nil {
+		s, err := r.store.Load(*r.config.State)
 		if err != nil {
-			return fmt.Errorf("Failed to retrieve by %T: %w", v.retriever, err)
+			return fmt.Errorf("Failed to load state: %w", err)
 		}
-		ls = append(ls, *l)
+		state = s
 	}
 
+	// approval rules are applied for the load of reviewers, and again after assignment for assigned pages
+	for i, v := range r.retrievers {
+		reviewhub.ApplyApprovalRules(&ls[i], v.config.ApprovalRules, v.config.Groups)
+	}
+	assignErr := r.assign(ctx, ls, state)
+	for i, v := range r.retrievers {
+		reviewhub.ApplyApprovalRules(&ls[i], v.config.ApprovalRules, v.config.Groups)
+		reviewhub.ApplySLA(&ls[i], v.config.SLA, v.config.Groups, r.users, now)
+	}
+
+	ctx = reviewhub.WithRuntime(ctx, r.runtime)
+	notifyErr := r.notify(ctx, ls, targets, state, now)
+
+	var escalateErr error
+	if escalate {
+		escalateErr = r.escalate(ctx, ls, targets)
+	}
+
+	var remindErr error
+	if state != nil {
+		remindErr = r.remind(ctx, ls, state, now)
+
+		if err := r.store.Save(*r.config.State, state); err != nil {
+			return fmt.Errorf("Failed to save state: %w", err)
+		}
+	}
+
+	return errors.Join(append(retrieveErrs, assignErr, notifyErr, escalateErr, remindErr)...)
+}
+
+// notify sends filtered lists to targets, continuing to other users of the notifier on errors unless fail fast
+func (r *ReviewHubRunner) notify(ctx context.Context, ls []reviewhub.ReviewList, targets map[string][]reviewhub.User, state *reviewhub.State, now time.Time) error {
+	var errs []error
 	for _, v := range r.notifiers {
-		for _, u := range r.users {
-			filtered := reviewhub.FilterReviewList(ls, u, false)
-			if err := v.notifier.Notify(v.config, u, filtered); err != nil {
-				log.Printf("Failed to notify to user by %T: %s", v.notifier, err)
-				break
+		ctx := ctx
+		if state != nil {
+			ctx = reviewhub.WithNotifierState(ctx, state.NotifierState(v.config.Name))
+		}
+
+		bn, batch := v.notifier.(reviewhub.BatchNotifier)
+		batch = batch && bn.Batch(v.config)
+
+		var ns []reviewhub.Notification
+		for _, u := range targets[v.config.Name] {
+			// a partial batch would drop users out of working hours from shared outputs
+			if in, _ := u.InWorkingHours(now); !in && !batch {
+				log.Printf("Defer notifying to %s by %s out of working hours", u.Name, v.config.Name)
+				r.setDeferred(v.config.Name, u, true)
+				continue
+			}
+
+			filtered := reviewhub.FilterReviewList(ls, u)
+			if state != nil {
+				filtered = state.FilterSnoozed(u, filtered, now)
+				filtered = state.MarkNew(v.config.Name, u, filtered)
+				if u.OnlyNew {
+					filtered = reviewhub.FilterNewPages(filtered)
+				}
 			}
+			ns = append(ns, reviewhub.Notification{User: u, ReviewLists: filtered})
+		}
+
+		if batch {
+			if len(ns) == 0 {
+				continue
+			}
+			err := bn.NotifyAll(ctx, v.config, ns)
+			if err != nil {
+				err = fmt.Errorf("Failed to notify by %s: %w", v.config.Name, err)
+				log.Print(err)
+				errs = append(errs, err)
+			}
+			// users notified before failures of others are recorded, and all fail by any other error
+			var nerrs reviewhub.NotifyErrors
+			if err != nil && !errors.As(err, &nerrs) {
+				continue
+			}
+			for _, n := range ns {
+				if _, failed := nerrs[n.User.Name]; !failed {
+					r.notified(v, n.User, ls, state, now)
+				}
+			}
+			continue
+		}
+
+		for _, n := range ns {
+			if err := v.notifier.Notify(ctx, v.config, n.User, n.ReviewLists); err != nil {
+				err = fmt.Errorf("Failed to notify to %s by %s: %w", n.User.Name, v.config.Name, err)
+				log.Print(err)
+				errs = append(errs, err)
+				if r.failFast() {
+					// skip the rest of this notifier only, others may still work
+					break
+				}
+				continue
+			}
+			r.notified(v, n.User, ls, state, now)
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-func parseBuiltinNotifier(config *reviewhub.NotifierConfig) (reviewhub.Notifier, error) {
-	if config.Type == "" {
-		return nil, fmt.Errorf("'type' field empty")
+// notified clears deferred and records notified pages of the user
+func (r *ReviewHubRunner) notified(v notifier, u reviewhub.User, ls []reviewhub.ReviewList, state *reviewhub.State, now time.Time) {
+	r.setDeferred(v.config.Name, u, false)
+
+	if state != nil {
+		// with only_new, filtered lacks pages notified before, so record from the whole list
+		state.Record(v.config.Name, u, reviewhub.FilterReviewList(ls, u), now)
+	}
+}
+
+// retrieve runs retrievers concurrently up to the concurrency limit, and returns lists in config order.
+// Failed sources are returned as lists with Error unless they abort the run by the failure policy,
+// and errors of non-optional ones are returned as failures to report after notifications.
+func (r *ReviewHubRunner) retrieve(ctx context.Context) (ls []reviewhub.ReviewList, failures []error, err error) {
+	// the cause is the first failure aborting the run
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	concurrency := r.config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
 	}
+	sem := make(chan struct{}, concurrency)
 
-	switch config.Type {
-	case "slack":
-		return new(slack.SlackNotifier), nil
-	case "stdout":
-		return new(stdout.StdoutNotifier), nil
+	ls = make([]reviewhub.ReviewList, len(r.retrievers))
+	errs := make([]error, len(r.retrievers))
+	var wg sync.WaitGroup
+	for i, v := range r.retrievers {
+		wg.Add(1)
+		go func(i int, v retriever) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			timeout := time.Duration(v.config.Timeout)
+			if timeout <= 0 {
+				timeout = defaultRetrieverTimeout
+			}
+			rctx, rcancel := context.WithTimeout(ctx, timeout)
+			defer rcancel()
+
+			l, err := v.retriever.Retrieve(rctx, v.config, r.users)
+			if err != nil {
+				errs[i] = err
+				if r.failFast() && !v.config.Optional {
+					// stop others since the run fails anyway
+					cancel(fmt.Errorf("Failed to retrieve %s: %w", v.config.Name, err))
+				}
+				return
+			}
+			ls[i] = *l
+		}(i, v)
+	}
+	wg.Wait()
+
+	// others stopped by the cancellation fail with errors like "signal: killed" of plugins, so they are dropped
+	if ctx.Err() != nil {
+		return nil, nil, context.Cause(ctx)
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		v := r.retrievers[i]
+		log.Printf("Failed to retrieve %s: %v", v.config.Name, err)
+		ls[i] = reviewhub.ReviewList{Name: v.config.Name, Error: err.Error()}
+		if !v.config.Optional {
+			// optional failures are only reported to users
+			failures = append(failures, fmt.Errorf("Failed to retrieve %s: %w", v.config.Name, err))
+		}
+	}
+	return ls, failures, nil
+}
+
+func (r *ReviewHubRunner) failFast() bool {
+	return r.config.FailurePolicy != reviewhub.FailurePolicyContinue
+}
+
+func (r *ReviewHubRunner) setDeferred(notifier string, user reviewhub.User, deferred bool) {
+	if !deferred {
+		delete(r.deferred[notifier], user.Name)
+		return
+	}
+
+	if r.deferred[notifier] == nil {
+		r.deferred[notifier] = map[string]*deferral{}
 	}
+	if _, ok := r.deferred[notifier][user.Name]; !ok {
+		// failures are kept while deferred again
+		r.deferred[notifier][user.Name] = &deferral{}
+	}
+}
 
-	return nil, ErrNotBuiltIn
+// Schedules returns cron expressions of notifiers keyed by notifier name, falling back to the default schedule
+func (r *ReviewHubRunner) Schedules() map[string]string {
+	schedules := map[string]string{}
+	for _, v := range r.notifiers {
+		if v.config.Schedule != "" {
+			schedules[v.config.Name] = v.config.Schedule
+		} else if r.config.Schedule != "" {
+			schedules[v.config.Name] = r.config.Schedule
+		}
+	}
+	return schedules
 }
 
-func parseBuiltinRetriever(config *reviewhub.RetrieverConfig) (reviewhub.Retriever, error) {
+func newNotifier(config *reviewhub.NotifierConfig) (reviewhub.Notifier, error) {
 	if config.Type == "" {
 		return nil, fmt.Errorf("'type' field empty")
 	}
+	return reviewhub.NewNotifier(config.Type)
+}
 
-	switch config.Type {
-	case "notion":
-		return new(notion.NotionRetriever), nil
-	case "github-discussions":
-		return new(ghdiscussions.GitHubDiscussionsRetriever), nil
+func newRetriever(config *reviewhub.RetrieverConfig) (reviewhub.Retriever, error) {
+	if config.Type == "" {
+		return nil, fmt.Errorf("'type' field empty")
 	}
+	return reviewhub.NewRetriever(config.Type)
+}
 
-	return nil, ErrNotBuiltIn
+func newStateStore(config *reviewhub.StateConfig) (reviewhub.StateStore, error) {
+	if config.Type == "" {
+		return nil, fmt.Errorf("'type' field empty")
+	}
+	return reviewhub.NewStateStore(config.Type)
 }
@@ -0,0 +1,493 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+func init() {
+	reviewhub.RegisterRetriever("fake", func() reviewhub.Retriever { return new(fakeRetriever) })
+	reviewhub.RegisterNotifier("fake", func() reviewhub.Notifier { return new(fakeNotifier) })
+}
+
+// fakeRetriever returns pages, and records write backs and comments
+type fakeRetriever struct {
+	pages []reviewhub.ReviewPage
+	err   error
+	// killed blocks until canceled, and fails like a killed plugin
+	killed bool
+
+	// assignErr fails write backs: page url -> error
+	assignErr map[string]error
+
+	mu       sync.Mutex
+	assigned map[string][]string // page url -> user names
+	comments []string            // page urls
+}
+
+func (f *fakeRetriever) Retrieve(ctx context.Context, config reviewhub.RetrieverConfig, users []reviewhub.User) (*reviewhub.ReviewList, error) {
+	if f.killed {
+		<-ctx.Done()
+		return nil, errors.New("signal: killed")
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &reviewhub.ReviewList{Name: config.Name, Pages: slices.Clone(f.pages)}, nil
+}
+
+func (f *fakeRetriever) AssignReviewers(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, reviewers []reviewhub.User) error {
+	if err := f.assignErr[page.Url]; err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.assigned == nil {
+		f.assigned = map[string][]string{}
+	}
+	f.assigned[page.Url] = names(reviewers)
+	return nil
+}
+
+// ValidateReviewers accepts users but ghost
+func (f *fakeRetriever) ValidateReviewers(users []reviewhub.User) error {
+	if slices.Contains(names(users), "ghost") {
+		return errors.New("ghost has no account")
+	}
+	return nil
+}
+
+func (f *fakeRetriever) Comment(ctx context.Context, config reviewhub.RetrieverConfig, page reviewhub.ReviewPage, message string, mentions []reviewhub.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.comments = append(f.comments, page.Url)
+	return nil
+}
+
+// fakeNotifier records notified page urls by user, and fails for users in fail
+type fakeNotifier struct {
+	batch bool
+	fail  map[string]bool
+
+	got     map[string][]string // user name -> page urls
+	calls   map[string]int      // user name -> count including failures
+	batches int
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, config reviewhub.NotifierConfig, user reviewhub.User, ls []reviewhub.ReviewList) error {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[user.Name]++
+	if f.fail[user.Name] {
+		return errors.New("notify failed")
+	}
+	if f.got == nil {
+		f.got = map[string][]string{}
+	}
+	f.got[user.Name] = urls(ls)
+	return nil
+}
+
+func (f *fakeNotifier) Batch(config reviewhub.NotifierConfig) bool {
+	return f.batch
+}
+
+func (f *fakeNotifier) NotifyAll(ctx context.Context, config reviewhub.NotifierConfig, ns []reviewhub.Notification) error {
+	f.batches++
+	errs := reviewhub.NotifyErrors{}
+	for _, n := range ns {
+		if err := f.Notify(ctx, config, n.User, n.ReviewLists); err != nil {
+			errs[n.User.Name] = err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// newTestRunner builds a runner of fake retrievers and notifiers in config order
+func newTestRunner(t *testing.T, config reviewhub.Config, rs []*fakeRetriever, ns []*fakeNotifier) *ReviewHubRunner {
+	t.Helper()
+	for i := range config.Retrievers {
+		config.Retrievers[i].Type = "fake"
+	}
+	for i := range config.Notifiers {
+		config.Notifiers[i].Type = "fake"
+	}
+
+	r, err := New(&config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i, f := range rs {
+		r.retrievers[i].retriever = f
+	}
+	for i, f := range ns {
+		r.notifiers[i].notifier = f
+	}
+	return r
+}
+
+func fileState(t *testing.T) *reviewhub.StateConfig {
+	return &reviewhub.StateConfig{
+		Type:     "file",
+		MetaData: map[any]any{"path": filepath.Join(t.TempDir(), "state.json")},
+	}
+}
+
+func users(names ...string) []reviewhub.User {
+	var us []reviewhub.User
+	for _, n := range names {
+		us = append(us, reviewhub.User{Name: n})
+	}
+	return us
+}
+
+func names(us []reviewhub.User) []string {
+	var s []string
+	for _, u := range us {
+		s = append(s, u.Name)
+	}
+	return s
+}
+
+// urls returns page urls of lists, marked with "*" if new
+func urls(ls []reviewhub.ReviewList) []string {
+	var s []string
+	for _, l := range ls {
+		for _, page := range l.Pages {
+			if page.IsNew {
+				s = append(s, page.Url+"*")
+			} else {
+				s = append(s, page.Url)
+			}
+		}
+	}
+	return s
+}
+
+func page(url, owner string, reviewers []reviewhub.User, approved ...string) reviewhub.ReviewPage {
+	return reviewhub.NewReviewPage(url, url, reviewhub.User{Name: owner}, users(approved...), reviewers)
+}
+
+func TestRunState(t *testing.T) {
+	alice := reviewhub.User{Name: "alice"}
+	bob := reviewhub.User{Name: "bob", OnlyNew: true}
+	both := []reviewhub.User{alice, bob}
+
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{
+		page("a", "erin", both),
+		page("approved-by-alice", "erin", both, "alice"),
+	}}
+	n := &fakeNotifier{}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{Name: "prs"}},
+		Notifiers:  []reviewhub.NotifierConfig{{Name: "n"}},
+		Users:      both,
+		State:      fileState(t),
+	}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	want := map[string][]string{
+		"alice": {"a*"},
+		"bob":   {"a*", "approved-by-alice*"},
+	}
+	for u, w := range want {
+		if !slices.Equal(n.got[u], w) {
+			t.Errorf("first run notified %s of %v, want %v", u, n.got[u], w)
+		}
+	}
+
+	// a notified page is not new any more, and dropped for only_new users
+	f.pages = append(f.pages, page("b", "erin", both))
+	n.got = nil
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	want = map[string][]string{
+		"alice": {"a", "b*"},
+		"bob":   {"b*"},
+	}
+	for u, w := range want {
+		if !slices.Equal(n.got[u], w) {
+			t.Errorf("second run notified %s of %v, want %v", u, n.got[u], w)
+		}
+	}
+
+	state, err := r.store.Load(*r.config.State)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := len(state.Notified["n"]["bob"]); got != 3 {
+		t.Errorf("state has %d pages notified to bob, want 3 including ones not new", got)
+	}
+}
+
+func TestRunFailurePolicy(t *testing.T) {
+	failing := errors.New("retrieve failed")
+	tests := []struct {
+		name     string
+		policy   string
+		optional bool
+
+		wantErr      bool
+		wantNotified []string // user names notified by the first notifier
+	}{
+		{name: "fail fast aborts", policy: reviewhub.FailurePolicyFailFast, wantErr: true},
+		{name: "optional never aborts", policy: reviewhub.FailurePolicyFailFast, optional: true, wantNotified: []string{"alice", "bob"}},
+		{name: "continue notifies then fails", policy: reviewhub.FailurePolicyContinue, wantErr: true, wantNotified: []string{"alice", "bob"}},
+		{name: "continue with optional", policy: reviewhub.FailurePolicyContinue, optional: true, wantNotified: []string{"alice", "bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", users("alice", "bob"))}}
+			ng := &fakeRetriever{err: failing}
+			n := &fakeNotifier{}
+			r := newTestRunner(t, reviewhub.Config{
+				Retrievers: []reviewhub.RetrieverConfig{
+					{Name: "ok"},
+					{Name: "ng", Optional: tt.optional},
+				},
+				Notifiers:     []reviewhub.NotifierConfig{{Name: "n"}},
+				Users:         users("alice", "bob"),
+				FailurePolicy: tt.policy,
+			}, []*fakeRetriever{ok, ng}, []*fakeNotifier{n})
+
+			err := r.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, failing) {
+				t.Errorf("Run() error = %v, want the retriever failure", err)
+			}
+
+			var notified []string
+			for _, u := range []string{"alice", "bob"} {
+				if got, ok := n.got[u]; ok {
+					notified = append(notified, u)
+					if !slices.Equal(got, []string{"a"}) {
+						t.Errorf("%s notified of %v, want pages of the working retriever", u, got)
+					}
+				}
+			}
+			if !slices.Equal(notified, tt.wantNotified) {
+				t.Errorf("notified %v, want %v", notified, tt.wantNotified)
+			}
+		})
+	}
+}
+
+func TestRunFirstFailure(t *testing.T) {
+	failing := errors.New("retrieve failed")
+	n := &fakeNotifier{}
+	r := newTestRunner(t, reviewhub.Config{
+		// the killed one comes first in config order
+		Retrievers: []reviewhub.RetrieverConfig{{Name: "plugin"}, {Name: "ng"}},
+		Notifiers:  []reviewhub.NotifierConfig{{Name: "n"}},
+		Users:      users("alice"),
+	}, []*fakeRetriever{{killed: true}, {err: failing}}, []*fakeNotifier{n})
+
+	err := r.Run(context.Background())
+	if !errors.Is(err, failing) || strings.Contains(err.Error(), "killed") {
+		t.Errorf("Run() error = %v, want only the first failure", err)
+	}
+	if len(n.got) != 0 {
+		t.Errorf("notified %v on abort", n.got)
+	}
+}
+
+func TestRunNotifyFailure(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   []string // user names notified by the failing notifier
+	}{
+		// the rest of the failing notifier is skipped
+		{reviewhub.FailurePolicyFailFast, nil},
+		{reviewhub.FailurePolicyContinue, []string{"bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			f := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", users("alice", "bob"))}}
+			failing := &fakeNotifier{fail: map[string]bool{"alice": true}}
+			other := &fakeNotifier{}
+			r := newTestRunner(t, reviewhub.Config{
+				Retrievers:    []reviewhub.RetrieverConfig{{Name: "prs"}},
+				Notifiers:     []reviewhub.NotifierConfig{{Name: "failing"}, {Name: "other"}},
+				Users:         users("alice", "bob"),
+				FailurePolicy: tt.policy,
+			}, []*fakeRetriever{f}, []*fakeNotifier{failing, other})
+
+			if err := r.Run(context.Background()); err == nil {
+				t.Error("Run() error = nil, want the notify failure")
+			}
+
+			var got []string
+			for u := range failing.got {
+				got = append(got, u)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("failing notifier notified %v, want %v", got, tt.want)
+			}
+			// other notifiers are not affected
+			if len(other.got) != 2 {
+				t.Errorf("other notifier notified %v, want both users", other.got)
+			}
+		})
+	}
+}
+
+func TestRunApprovalRules(t *testing.T) {
+	reviewers := users("alice", "bob", "carol")
+	tests := []struct {
+		name  string
+		rules []reviewhub.ApprovalRule
+		want  []string // user names notified of the page
+	}{
+		{"all reviewers by default", nil, []string{"alice", "bob"}},
+		{"quorum met", []reviewhub.ApprovalRule{{Count: 1}}, nil},
+		{"quorum not met", []reviewhub.ApprovalRule{{Count: 2}}, []string{"alice", "bob"}},
+		{"group not approved", []reviewhub.ApprovalRule{{From: []string{"leads"}, Count: 1}}, []string{"alice", "bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", reviewers, "carol")}}
+			n := &fakeNotifier{}
+			r := newTestRunner(t, reviewhub.Config{
+				Retrievers: []reviewhub.RetrieverConfig{{Name: "prs", ApprovalRules: tt.rules}},
+				Notifiers:  []reviewhub.NotifierConfig{{Name: "n"}},
+				Users:      reviewers,
+				Groups:     reviewhub.Groups{"leads": {"alice", "bob"}},
+			}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+			if err := r.Run(context.Background()); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got []string
+			for _, u := range []string{"alice", "bob", "carol"} {
+				if len(n.got[u]) > 0 {
+					got = append(got, u)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("notified %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	away := reviewhub.User{Name: "away", WorkingHours: &reviewhub.WorkingHours{Start: "00:00", End: "00:00"}}
+	all := append(users("alice"), away)
+
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", all)}}
+	batch := &fakeNotifier{batch: true}
+	single := &fakeNotifier{}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{Name: "prs"}},
+		Notifiers:  []reviewhub.NotifierConfig{{Name: "batch"}, {Name: "single"}},
+		Users:      all,
+	}, []*fakeRetriever{f}, []*fakeNotifier{batch, single})
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// batches are shared outputs, so they have users out of working hours
+	if batch.batches != 1 || len(batch.got) != 2 {
+		t.Errorf("batch notifier called %d times for %v, want once for both users", batch.batches, batch.got)
+	}
+	if _, ok := single.got["away"]; ok || len(single.got) != 1 {
+		t.Errorf("single notifier notified %v, want only alice in working hours", single.got)
+	}
+	if r.deferred["single"]["away"] == nil || r.deferred["batch"]["away"] != nil {
+		t.Errorf("deferred = %v, want away deferred only by the single notifier", r.deferred)
+	}
+}
+
+func TestRunBatchPartialFailure(t *testing.T) {
+	f := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", users("alice", "bob"))}}
+	n := &fakeNotifier{batch: true, fail: map[string]bool{"bob": true}}
+	r := newTestRunner(t, reviewhub.Config{
+		Retrievers: []reviewhub.RetrieverConfig{{Name: "prs"}},
+		Notifiers:  []reviewhub.NotifierConfig{{Name: "n"}},
+		Users:      users("alice", "bob"),
+		State:      fileState(t),
+	}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+	if err := r.Run(context.Background()); err == nil {
+		t.Fatal("Run() error = nil, want the failure of bob")
+	}
+
+	// alice was delivered, so only bob still has the page new
+	n.fail = nil
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	want := map[string][]string{"alice": {"a"}, "bob": {"a*"}}
+	for u, w := range want {
+		if !slices.Equal(n.got[u], w) {
+			t.Errorf("second run notified %s of %v, want %v", u, n.got[u], w)
+		}
+	}
+}
+
+func TestRunDeferred(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+
+	tests := []struct {
+		name string
+		fail bool
+		runs []time.Time
+		want int // notify calls to the deferred user
+	}{
+		{"notified once in working hours", false, []time.Time{at(8, 0), at(9, 0), at(9, 1), at(10, 0)}, 1},
+		// retried after 5 and 10 minutes, then given up
+		{"given up after failures", true, []time.Time{at(9, 0), at(9, 1), at(9, 5), at(9, 14), at(9, 15), at(10, 0)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			late := reviewhub.User{Name: "late", Timezone: "UTC", WorkingHours: &reviewhub.WorkingHours{Start: "09:00", End: "18:00"}}
+			f := &fakeRetriever{pages: []reviewhub.ReviewPage{page("a", "erin", []reviewhub.User{late})}}
+			n := &fakeNotifier{fail: map[string]bool{"late": tt.fail}}
+			r := newTestRunner(t, reviewhub.Config{
+				Retrievers:    []reviewhub.RetrieverConfig{{Name: "prs"}},
+				Notifiers:     []reviewhub.NotifierConfig{{Name: "n"}},
+				Users:         []reviewhub.User{late},
+				FailurePolicy: reviewhub.FailurePolicyContinue,
+			}, []*fakeRetriever{f}, []*fakeNotifier{n})
+
+			targets := map[string][]reviewhub.User{"n": r.users}
+			if err := r.run(context.Background(), targets, at(6, 0), true); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if r.deferred["n"]["late"] == nil {
+				t.Fatal("User out of working hours is not deferred")
+			}
+
+			for _, now := range tt.runs {
+				r.runDeferred(context.Background(), now)
+			}
+			if got := n.calls["late"]; got != tt.want {
+				t.Errorf("notified %d times, want %d", got, tt.want)
+			}
+			if d := r.deferred["n"]["late"]; d != nil {
+				t.Errorf("deferred = %+v, want cleared", d)
+			}
+		})
+	}
+}
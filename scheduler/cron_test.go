@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"*/15 9-18 * * mon-fri", false},
+		{"0 9 1,15 jan,jul 0", false},
+		{"0 0 * * 7", false},
+		{"@daily", false},
+		{" @hourly ", false},
+		{"5/10 * * * *", false},
+		{"* * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"10-5 * * * *", true},
+		{"* * * foo *", true},
+		{"@reboot", true},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	utc := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	local := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", utc("2026-10-18 10:00"), utc("2026-10-18 10:01")},
+		{"seconds truncated", "* * * * *", utc("2026-10-18 10:00").Add(30 * time.Second), utc("2026-10-18 10:01")},
+		{"daily later today", "0 9 * * *", utc("2026-10-18 08:59"), utc("2026-10-18 09:00")},
+		{"daily tomorrow", "0 9 * * *", utc("2026-10-18 09:00"), utc("2026-10-19 09:00")},
+		{"minute step", "*/15 * * * *", utc("2026-10-18 10:16"), utc("2026-10-18 10:30")},
+		{"step from value", "5/20 * * * *", utc("2026-10-18 10:26"), utc("2026-10-18 10:45")},
+		{"hour range", "0 9-17 * * *", utc("2026-10-18 17:30"), utc("2026-10-19 09:00")},
+		{"range with step", "0 8-18/4 * * *", utc("2026-10-18 12:01"), utc("2026-10-18 16:00")},
+		{"list", "0 0 1,15 * *", utc("2026-10-02 00:00"), utc("2026-10-15 00:00")},
+		// 2026-10-18 is a sunday
+		{"weekdays", "0 9 * * mon-fri", utc("2026-10-17 10:00"), utc("2026-10-19 09:00")},
+		{"sunday as 7", "0 9 * * 7", utc("2026-10-12 10:00"), utc("2026-10-18 09:00")},
+		{"month names", "0 0 1 jan *", utc("2026-10-18 00:00"), utc("2027-01-01 00:00")},
+		{"dom or dow", "0 0 13 * fri", utc("2026-10-10 00:00"), utc("2026-10-13 00:00")},
+		{"dow or dom", "0 0 13 * fri", utc("2026-10-14 00:00"), utc("2026-10-16 00:00")},
+		{"dom step is unrestricted", "0 0 */2 * mon", utc("2026-10-18 00:00"), utc("2026-10-19 00:00")},
+		{"dow step is unrestricted", "0 0 13 * */1", utc("2026-10-10 00:00"), utc("2026-10-13 00:00")},
+		{"feb 29", "0 0 29 2 *", utc("2026-03-01 00:00"), utc("2028-02-29 00:00")},
+		{"month end", "0 0 31 * *", utc("2026-09-01 00:00"), utc("2026-10-31 00:00")},
+		{"never", "0 0 30 2 *", utc("2026-01-01 00:00"), time.Time{}},
+		{"descriptor", "@weekly", utc("2026-10-14 00:00"), utc("2026-10-18 00:00")},
+		{"in location", "0 9 * * *", local("2026-10-18 08:00"), local("2026-10-18 09:00")},
+		// clocks go 2:00 -> 3:00 on 2026-03-08 and 2:00 -> 1:00 on 2026-11-01
+		{"dst gap skipped", "30 2 * * *", local("2026-03-08 00:00"), local("2026-03-09 02:30")},
+		{"dst gap hourly", "0 * * * *", local("2026-03-08 01:30"), local("2026-03-08 03:00")},
+		{"dst repeated hour first", "30 1 * * *", local("2026-11-01 00:00"), local("2026-11-01 01:30")},
+		{"dst repeated hour once", "30 1 * * *", local("2026-11-01 01:30"), local("2026-11-02 01:30")},
+		{"dst after repeated hour", "0 2 * * *", local("2026-11-01 01:30"), local("2026-11-01 02:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
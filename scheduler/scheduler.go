@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Schedule *Schedule
+}
+
+// Run calls fn with names of jobs due at each scheduled time until ctx is canceled.
+// fn is called synchronously, so a running fn is not interrupted on cancel.
+func Run(ctx context.Context, loc *time.Location, jobs []Job, fn func(names []string)) error {
+	if len(jobs) == 0 {
+		return fmt.Errorf("No job scheduled")
+	}
+
+	last := time.Now().In(loc)
+	for {
+		next, due := nextDue(jobs, last, time.Now().In(loc))
+		if next.IsZero() {
+			return fmt.Errorf("No job will run anymore")
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		fn(due)
+		last = next
+	}
+}
+
+// nextDue returns the earliest time after last and names of jobs due then.
+// Runs missed until now, like while fn is running long, are collapsed into the latest one of each job.
+func nextDue(jobs []Job, last, now time.Time) (time.Time, []string) {
+	var next time.Time
+	var due []string
+	for _, j := range jobs {
+		t := j.Schedule.Next(last)
+		if t.IsZero() {
+			continue
+		}
+		for t.Before(now) {
+			n := j.Schedule.Next(t)
+			if n.IsZero() || n.After(now) {
+				break
+			}
+			t = n
+		}
+
+		switch {
+		case next.IsZero() || t.Before(next):
+			next = t
+			due = []string{j.Name}
+		case t.Equal(next):
+			due = append(due, j.Name)
+		}
+	}
+	return next, due
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNextDue(t *testing.T) {
+	mustParse := func(expr string) *Schedule {
+		s, err := Parse(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	at := func(s string) time.Time {
+		v, err := time.Parse("15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.AddDate(2026, 0, 0)
+	}
+
+	fiveMin := Job{Name: "five", Schedule: mustParse("*/5 * * * *")}
+	hourly := Job{Name: "hourly", Schedule: mustParse("0 * * * *")}
+	daily := Job{Name: "daily", Schedule: mustParse("0 9 * * *")}
+
+	tests := []struct {
+		name      string
+		jobs      []Job
+		last, now time.Time
+		want      time.Time
+		wantNames []string
+	}{
+		{"waits for next", []Job{fiveMin}, at("10:00"), at("10:01"), at("10:05"), []string{"five"}},
+		{"same time", []Job{fiveMin, hourly}, at("10:56"), at("10:57"), at("11:00"), []string{"five", "hourly"}},
+		{"earliest", []Job{hourly, fiveMin}, at("10:56"), at("10:56"), at("11:00"), []string{"hourly", "five"}},
+		{"missed runs collapse", []Job{fiveMin}, at("10:00"), at("10:32"), at("10:30"), []string{"five"}},
+		{"missed run at now", []Job{fiveMin}, at("10:00"), at("10:30"), at("10:30"), []string{"five"}},
+		{"missed and future", []Job{fiveMin, daily}, at("08:00"), at("08:12"), at("08:10"), []string{"five"}},
+		{"no jobs", nil, at("10:00"), at("10:00"), time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, names := nextDue(tt.jobs, tt.last, tt.now)
+			if !got.Equal(tt.want) || !slices.Equal(names, tt.wantNames) {
+				t.Errorf("nextDue() = %v %v, want %v %v", got, names, tt.want, tt.wantNames)
+			}
+		})
+	}
+}
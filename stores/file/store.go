@@ -0,0 +1,67 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/ry023/reviewhub/reviewhub"
+)
+
+// FileStateStore persists state as a json file.
+// The file can be committed or cached between GitHub Actions runs.
+type FileStateStore struct {
+}
+
+func init() {
+	reviewhub.RegisterStateStore("file", func() reviewhub.StateStore { return new(FileStateStore) })
+}
+
+type MetaData struct {
+	Path string `yaml:"path" validate:"required"`
+}
+
+func (s *FileStateStore) Load(config reviewhub.StateConfig) (*reviewhub.State, error) {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(meta.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		// first run
+		return reviewhub.NewState(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := reviewhub.NewState()
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *FileStateStore) Save(config reviewhub.StateConfig, state *reviewhub.State) error {
+	meta, err := reviewhub.ParseMetaData[MetaData](config.MetaData)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(meta.Path), 0o755); err != nil {
+		return err
+	}
+
+	// write to temporary file and rename it not to break state on failure
+	tmp := meta.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, meta.Path)
+}